@@ -0,0 +1,383 @@
+package rundeck
+
+import (
+	"fmt"
+)
+
+// JobBuilder provides a concise way to construct a JobDetail in Go code.
+//
+// Create a builder with NewJob, chain calls to describe the job, and then
+// call Build to obtain the finished JobDetail. The builder takes care of
+// allocating the nested structures and filling in the defaults that Rundeck
+// expects, and Build validates the result with JobDetail.Validate.
+//
+// Methods that add commands append to the job's command sequence in the
+// order they are called. Methods that modify a command, such as OnError,
+// apply to the most recently-added command.
+type JobBuilder struct {
+	job      *JobDetail
+	problems []string
+}
+
+// NewJob starts building a job with the given name within the named project.
+//
+// The job starts out with execution and scheduling enabled and with
+// an empty node-first command sequence.
+func NewJob(projectName string, name string) *JobBuilder {
+	return &JobBuilder{
+		job: &JobDetail{
+			Name:             name,
+			ProjectName:      projectName,
+			ExecutionEnabled: true,
+			ScheduleEnabled:  true,
+			CommandSequence: &JobCommandSequence{
+				OrderingStrategy: "node-first",
+			},
+		},
+	}
+}
+
+// ID sets the UUID of the job, which is required when the result will be
+// passed to CreateOrUpdateJob.
+func (b *JobBuilder) ID(id string) *JobBuilder {
+	b.job.ID = id
+	return b
+}
+
+// Group sets the group the job belongs to, as a slash-separated path.
+func (b *JobBuilder) Group(name string) *JobBuilder {
+	b.job.GroupName = name
+	return b
+}
+
+// Description sets the description of the job.
+func (b *JobBuilder) Description(description string) *JobBuilder {
+	b.job.Description = description
+	return b
+}
+
+// LogLevel sets the log level for executions of the job.
+func (b *JobBuilder) LogLevel(level string) *JobBuilder {
+	b.job.LogLevel = level
+	return b
+}
+
+// Disabled prevents the job from being executed.
+func (b *JobBuilder) Disabled() *JobBuilder {
+	b.job.ExecutionEnabled = false
+	return b
+}
+
+// AllowConcurrentExecutions permits more than one execution of the job to
+// run at the same time.
+func (b *JobBuilder) AllowConcurrentExecutions() *JobBuilder {
+	b.job.AllowConcurrentExecutions = true
+	return b
+}
+
+// Timeout sets the maximum run time of the job, either as a number of
+// seconds or as a duration like "1h30m".
+func (b *JobBuilder) Timeout(timeout string) *JobBuilder {
+	b.job.Timeout = timeout
+	return b
+}
+
+// Retry sets the number of times a failed execution will be retried.
+func (b *JobBuilder) Retry(retry string) *JobBuilder {
+	b.job.Retry = retry
+	return b
+}
+
+// KeepGoing causes the job to continue with subsequent commands after
+// a command fails.
+func (b *JobBuilder) KeepGoing() *JobBuilder {
+	b.job.CommandSequence.ContinueOnError = true
+	return b
+}
+
+// Strategy sets the ordering strategy for the job's command sequence.
+func (b *JobBuilder) Strategy(strategy string) *JobBuilder {
+	b.job.CommandSequence.OrderingStrategy = strategy
+	return b
+}
+
+// StrategyPlugin sets the ordering strategy for the job's command sequence to
+// the given workflow strategy plugin, such as "ruleset", with the given
+// configuration.
+func (b *JobBuilder) StrategyPlugin(strategy string, config map[string]string) *JobBuilder {
+	seq := b.job.CommandSequence
+	seq.OrderingStrategy = strategy
+	if seq.PluginConfig == nil {
+		seq.PluginConfig = &JobWorkflowPluginConfig{}
+	}
+	if seq.PluginConfig.WorkflowStrategy == nil {
+		seq.PluginConfig.WorkflowStrategy = JobWorkflowStrategyConfig{}
+	}
+	seq.PluginConfig.WorkflowStrategy[strategy] = JobPluginConfig(config)
+	return b
+}
+
+// LogFilter adds a log filter plugin, such as "key-value-data" or
+// "mask-passwords", that applies to the output of every command in the job.
+func (b *JobBuilder) LogFilter(filterType string, config map[string]string) *JobBuilder {
+	seq := b.job.CommandSequence
+	if seq.PluginConfig == nil {
+		seq.PluginConfig = &JobWorkflowPluginConfig{}
+	}
+	seq.PluginConfig.LogFilters = append(seq.PluginConfig.LogFilters, JobLogFilter{
+		Type:   filterType,
+		Config: JobLogFilterConfig(config),
+	})
+	return b
+}
+
+// Command appends an arbitrary command to the job's command sequence. The
+// more specific methods like Exec and Script should be preferred where
+// possible.
+func (b *JobBuilder) Command(cmd JobCommand) *JobBuilder {
+	seq := b.job.CommandSequence
+	seq.Commands = append(seq.Commands, cmd)
+	return b
+}
+
+// Exec appends a literal shell command to the job's command sequence.
+func (b *JobBuilder) Exec(command string) *JobBuilder {
+	return b.Command(JobCommand{
+		ShellCommand: command,
+	})
+}
+
+// Script appends an inline script to the job's command sequence, to be run
+// with the given arguments.
+func (b *JobBuilder) Script(script string, args string) *JobBuilder {
+	return b.Command(JobCommand{
+		Script:         script,
+		ScriptFileArgs: args,
+	})
+}
+
+// ScriptFile appends a command that runs a pre-existing file on the target
+// nodes with the given arguments.
+func (b *JobBuilder) ScriptFile(path string, args string) *JobBuilder {
+	return b.Command(JobCommand{
+		ScriptFile:     path,
+		ScriptFileArgs: args,
+	})
+}
+
+// JobRef appends a command that runs another job, identified by its group
+// and name, with the given argument string.
+func (b *JobBuilder) JobRef(groupName string, name string, args string) *JobBuilder {
+	return b.Command(JobCommand{
+		Job: &JobCommandJobRef{
+			Name:      name,
+			GroupName: groupName,
+			Arguments: JobCommandJobRefArguments(args),
+		},
+	})
+}
+
+// StepPlugin appends a command that runs the given workflow step plugin.
+func (b *JobBuilder) StepPlugin(pluginType string, config map[string]string) *JobBuilder {
+	return b.Command(JobCommand{
+		StepPlugin: &JobPlugin{
+			Type:   pluginType,
+			Config: JobPluginConfig(config),
+		},
+	})
+}
+
+// NodeStepPlugin appends a command that runs the given node step plugin.
+func (b *JobBuilder) NodeStepPlugin(pluginType string, config map[string]string) *JobBuilder {
+	return b.Command(JobCommand{
+		NodeStepPlugin: &JobPlugin{
+			Type:   pluginType,
+			Config: JobPluginConfig(config),
+		},
+	})
+}
+
+// CommandDescription sets the description of the most recently-added command.
+func (b *JobBuilder) CommandDescription(description string) *JobBuilder {
+	if cmd := b.lastCommand("CommandDescription"); cmd != nil {
+		cmd.Description = description
+	}
+	return b
+}
+
+// CommandLogFilter adds a log filter plugin that applies to the output of the
+// most recently-added command.
+func (b *JobBuilder) CommandLogFilter(filterType string, config map[string]string) *JobBuilder {
+	if cmd := b.lastCommand("CommandLogFilter"); cmd != nil {
+		if cmd.Plugins == nil {
+			cmd.Plugins = &JobCommandPlugins{}
+		}
+		cmd.Plugins.LogFilters = append(cmd.Plugins.LogFilters, JobLogFilter{
+			Type:   filterType,
+			Config: JobLogFilterConfig(config),
+		})
+	}
+	return b
+}
+
+// OnError sets the error handler for the most recently-added command.
+//
+// If continueOnSuccess is set, a successful error handler allows the job
+// to continue even when KeepGoing is not set.
+func (b *JobBuilder) OnError(handler JobCommand, continueOnSuccess bool) *JobBuilder {
+	if cmd := b.lastCommand("OnError"); cmd != nil {
+		handler.ContinueOnError = continueOnSuccess
+		cmd.ErrorHandler = &handler
+	}
+	return b
+}
+
+// Option adds an option to the job.
+func (b *JobBuilder) Option(option JobOption) *JobBuilder {
+	if b.job.OptionsConfig == nil {
+		b.job.OptionsConfig = &JobOptions{
+			PreserveOrder: true,
+		}
+	}
+	b.job.OptionsConfig.Options = append(b.job.OptionsConfig.Options, option)
+	return b
+}
+
+// Nodes causes the job to be dispatched to the nodes matching the given
+// node filter query, rather than running only on the Rundeck server.
+//
+// The nodes are selected by default and are run one at a time, stopping
+// at the first failure; use Dispatch to change this.
+func (b *JobBuilder) Nodes(query string) *JobBuilder {
+	b.job.NodeFilter = &JobNodeFilter{
+		Query: query,
+	}
+	if b.job.Dispatch == nil {
+		b.Dispatch(1, false)
+	}
+	if b.job.NodesSelectedByDefault == nil {
+		b.job.NodesSelectedByDefault = &Boolean{Value: true}
+	}
+	return b
+}
+
+// Dispatch sets the number of nodes that the job will run on concurrently
+// and whether it will continue on the remaining nodes after one fails.
+func (b *JobBuilder) Dispatch(threadCount int, continueOnError bool) *JobBuilder {
+	b.job.Dispatch = &JobDispatch{
+		ExcludePrecedence: &Boolean{Value: true},
+		MaxThreadCount:    threadCount,
+		ContinueOnError:   continueOnError,
+		RankOrder:         "ascending",
+	}
+	return b
+}
+
+// Schedule sets a schedule on which the job will run automatically.
+//
+// Any of the month, year and time fields left empty in the given schedule
+// default to "*", "*" and "0" seconds respectively, and if neither
+// a day of the month nor a weekday is given then the job runs every day.
+// These defaults aren't applied to a schedule with a Crontab, which
+// replaces the other fields.
+func (b *JobBuilder) Schedule(schedule JobSchedule) *JobBuilder {
+	if schedule.Crontab != "" {
+		b.job.Schedule = &schedule
+		b.job.ScheduleEnabled = true
+		return b
+	}
+	if schedule.Month.Month == "" {
+		schedule.Month.Month = "*"
+	}
+	if schedule.Year.Year == "" {
+		schedule.Year.Year = "*"
+	}
+	if schedule.Time.Seconds == "" {
+		schedule.Time.Seconds = "0"
+	}
+	if schedule.DayOfMonth == nil && schedule.WeekDay == nil {
+		schedule.WeekDay = &JobScheduleWeekDay{
+			Day: "*",
+		}
+	}
+	b.job.Schedule = &schedule
+	b.job.ScheduleEnabled = true
+	return b
+}
+
+// DailyAt is a shorthand for a Schedule that runs the job every day at the
+// given time.
+func (b *JobBuilder) DailyAt(hour int, minute int) *JobBuilder {
+	return b.Schedule(JobSchedule{
+		Time: JobScheduleTime{
+			Hour:   fmt.Sprintf("%02d", hour),
+			Minute: fmt.Sprintf("%02d", minute),
+		},
+	})
+}
+
+// Notify adds a notification to be sent on the given event, which must be
+// one of "onstart", "onsuccess" or "onfailure".
+func (b *JobBuilder) Notify(event string, notification Notification) *JobBuilder {
+	if b.job.Notification == nil {
+		b.job.Notification = &JobNotification{}
+	}
+	n := &notification
+	switch event {
+	case "onstart":
+		b.job.Notification.OnStart = n
+	case "onsuccess":
+		b.job.Notification.OnSuccess = n
+	case "onfailure":
+		b.job.Notification.OnFailure = n
+	default:
+		b.problems = append(b.problems, fmt.Sprintf("notification event %q must be one of onstart, onsuccess or onfailure", event))
+	}
+	return b
+}
+
+// NotifyEmail is a shorthand for a Notify that sends an email with the given
+// subject to the given recipients.
+func (b *JobBuilder) NotifyEmail(event string, subject string, recipients ...string) *JobBuilder {
+	return b.Notify(event, Notification{
+		Email: &EmailNotification{
+			Recipients: NotificationEmails(recipients),
+			Subject:    subject,
+		},
+	})
+}
+
+// NotifyWebHook is a shorthand for a Notify that calls the given URLs.
+func (b *JobBuilder) NotifyWebHook(event string, urls ...string) *JobBuilder {
+	return b.Notify(event, Notification{
+		WebHook: &WebHookNotification{
+			Urls: NotificationUrls(urls),
+		},
+	})
+}
+
+// Build returns the job that has been described, or a *JobValidationError
+// if the builder was misused or the resulting job is not valid.
+//
+// The builder should not be used after calling Build.
+func (b *JobBuilder) Build() (*JobDetail, error) {
+	problems := b.problems
+	if err := b.job.Validate(); err != nil {
+		problems = append(problems, err.(*JobValidationError).Problems...)
+	}
+	if len(problems) > 0 {
+		return nil, &JobValidationError{
+			Problems: problems,
+		}
+	}
+	return b.job, nil
+}
+
+func (b *JobBuilder) lastCommand(method string) *JobCommand {
+	commands := b.job.CommandSequence.Commands
+	if len(commands) == 0 {
+		b.problems = append(b.problems, fmt.Sprintf("%s called before any command was added", method))
+		return nil
+	}
+	return &commands[len(commands)-1]
+}
@@ -0,0 +1,95 @@
+package rundeck
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestJobBuilder(t *testing.T) {
+	job, err := NewJob("example", "deploy").
+		Group("ops/deploy").
+		Description("Deploys the app").
+		Option(JobOption{Name: "version", IsRequired: true}).
+		Exec("echo ${option.version}").
+		Script("#!/bin/sh\ntrue", "-v").
+		OnError(JobCommand{ShellCommand: "echo failed"}, true).
+		Nodes("tags: web").
+		DailyAt(3, 5).
+		NotifyEmail("onfailure", "deploy failed", "ops@example.com", "dev@example.com").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	xmlBytes, err := xml.Marshal(job)
+	if err != nil {
+		t.Fatalf("error marshalling built job: %s", err)
+	}
+	expected := `<job>` +
+		`<name>deploy</name>` +
+		`<group>ops/deploy</group>` +
+		`<context><project>example</project><options preserveOrder="true"><option name="version" required="true"></option></options></context>` +
+		`<description>Deploys the app</description>` +
+		`<executionEnabled>true</executionEnabled>` +
+		`<dispatch><excludePrecedence>true</excludePrecedence><threadcount>1</threadcount><keepgoing>false</keepgoing><rankOrder>ascending</rankOrder></dispatch>` +
+		`<sequence keepgoing="false" strategy="node-first">` +
+		`<command><exec>echo ${option.version}</exec></command>` +
+		`<command><errorhandler keepgoingOnSuccess="true"><exec>echo failed</exec></errorhandler><script>#!/bin/sh&#xA;true</script><scriptargs>-v</scriptargs></command>` +
+		`</sequence>` +
+		`<notification><onfailure><email recipients="ops@example.com,dev@example.com" subject="deploy failed"></email></onfailure></notification>` +
+		`<nodefilters><filter>tags: web</filter></nodefilters>` +
+		`<nodesSelectedByDefault>true</nodesSelectedByDefault>` +
+		`<schedule><time hour="03" minute="05" seconds="0"></time><month month="*"></month><weekday day="*"></weekday><year year="*"></year></schedule>` +
+		`<scheduleEnabled>true</scheduleEnabled>` +
+		`</job>`
+	if string(xmlBytes) != expected {
+		t.Errorf("got %s, but wanted %s", xmlBytes, expected)
+	}
+}
+
+func TestJobBuilderCrontab(t *testing.T) {
+	job, err := NewJob("example", "poll").
+		Exec("true").
+		Schedule(JobSchedule{Crontab: "0 */15 * ? * * *"}).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.Schedule.WeekDay != nil || job.Schedule.Month.Month != "" || job.Schedule.Year.Year != "" || job.Schedule.Time.Seconds != "" {
+		t.Errorf("got schedule %#v, but wanted only the crontab", job.Schedule)
+	}
+
+	xmlBytes, err := xml.Marshal(job)
+	if err != nil {
+		t.Fatalf("error marshalling built job: %s", err)
+	}
+	if expected := `<schedule crontab="0 */15 * ? * * *"></schedule>`; !strings.Contains(string(xmlBytes), expected) {
+		t.Errorf("got %s, but wanted it to contain %s", xmlBytes, expected)
+	}
+}
+
+func TestJobBuilderErrors(t *testing.T) {
+	_, err := NewJob("example", "").
+		OnError(JobCommand{ShellCommand: "echo failed"}, false).
+		Notify("onlunch", Notification{}).
+		Build()
+	valErr, ok := err.(*JobValidationError)
+	if !ok {
+		t.Fatalf("got %#v, but wanted *JobValidationError", err)
+	}
+	expected := []string{
+		"OnError called before any command was added",
+		`notification event "onlunch" must be one of onstart, onsuccess or onfailure`,
+		"name is required",
+		"sequence must contain at least one command",
+	}
+	if len(valErr.Problems) != len(expected) {
+		t.Fatalf("got problems %q, but wanted %q", valErr.Problems, expected)
+	}
+	for i := range expected {
+		if valErr.Problems[i] != expected[i] {
+			t.Errorf("problem %d is %q, but wanted %q", i, valErr.Problems[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package rundeck
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints whose responses can be cached, for use as keys in
+// CacheConfig.TTLs. Any endpoint pattern reported in RequestEvent.Endpoint
+// may be used, but these are the ones most worth caching.
+const (
+	CacheEndpointProjects   = "projects"
+	CacheEndpointProject    = "project/{project}"
+	CacheEndpointJobs       = "project/{project}/jobs"
+	CacheEndpointJobExport  = "jobs/export"
+	CacheEndpointJob        = "job/{id}"
+	CacheEndpointSystemInfo = "system/info"
+)
+
+// CacheConfig enables caching of GET responses for read-heavy clients.
+type CacheConfig struct {
+	// TTLs gives the time for which responses from each endpoint are
+	// considered fresh, keyed by the endpoint pattern as reported in
+	// RequestEvent.Endpoint, such as "project/{project}/jobs". Endpoints not
+	// listed are never cached.
+	//
+	// Once a response is stale, it is revalidated with the server using
+	// If-None-Match or If-Modified-Since if the server gave an ETag or
+	// Last-Modified header, and otherwise fetched again.
+	TTLs map[string]time.Duration
+}
+
+// cache holds cached responses for a client. It is safe for concurrent use.
+//
+// Any write made through the client invalidates the cached responses that it
+// may have affected: those in the same family of endpoints (jobs, projects,
+// keys, and so on) and, when the write relates to a single project, only
+// those for that project. Deleting or reconfiguring a project also
+// invalidates everything cached for that project.
+type cache struct {
+	ttls map[string]time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	endpoint     string
+	project      string
+	body         []byte
+	etag         string
+	lastModified string
+	expires      time.Time
+}
+
+func newCache(config *CacheConfig) *cache {
+	if config == nil || len(config.TTLs) == 0 {
+		return nil
+	}
+	return &cache{
+		ttls:    config.TTLs,
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// cacheKey identifies a cached response. Responses in different formats are
+// cached separately.
+func cacheKey(httpReq *http.Request) string {
+	return httpReq.Header.Get("Accept") + " " + httpReq.URL.String()
+}
+
+// lookup returns the cached entry for the request if its endpoint is
+// cacheable, along with whether the entry is still fresh. The entry is nil
+// if there is none.
+func (c *cache) lookup(ev *RequestEvent) (entry *cacheEntry, fresh bool) {
+	if c == nil || ev.Method != "GET" || c.ttls[ev.Endpoint] <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry = c.entries[cacheKey(ev.Request)]
+	if entry == nil {
+		return nil, false
+	}
+	return entry, time.Now().Before(entry.expires)
+}
+
+// store caches a successful response, if its endpoint is cacheable.
+func (c *cache) store(ev *RequestEvent, body []byte) {
+	if c == nil || ev.Method != "GET" {
+		return
+	}
+	ttl := c.ttls[ev.Endpoint]
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[cacheKey(ev.Request)] = &cacheEntry{
+		endpoint:     ev.Endpoint,
+		project:      ev.Project,
+		body:         body,
+		etag:         ev.Response.Header.Get("ETag"),
+		lastModified: ev.Response.Header.Get("Last-Modified"),
+		expires:      time.Now().Add(ttl),
+	}
+}
+
+// refresh marks an entry as fresh again after the server confirmed it is
+// unchanged.
+func (c *cache) refresh(entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.expires = time.Now().Add(c.ttls[entry.endpoint])
+}
+
+// invalidate discards the entries that may be affected by the given write.
+func (c *cache) invalidate(ev *RequestEvent) {
+	if c == nil || ev.Method == "GET" || ev.Method == "HEAD" {
+		return
+	}
+	family := endpointFamily(ev.Endpoint)
+	projectWrite := ev.Project != "" && (ev.Endpoint == "project/{project}" || ev.Endpoint == "project/{project}/config")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		sameProject := ev.Project == "" || entry.project == "" || entry.project == ev.Project
+		if (endpointFamily(entry.endpoint) == family && sameProject) || (projectWrite && entry.project == ev.Project) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// clear discards all entries.
+func (c *cache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*cacheEntry{}
+}
+
+// endpointFamily groups endpoints that read or write the same kind of
+// resource.
+func endpointFamily(endpoint string) string {
+	parts := strings.Split(endpoint, "/")
+	switch {
+	case parts[0] == "job" || parts[0] == "jobs":
+		return "jobs"
+	case parts[0] == "projects":
+		return "projects"
+	case parts[0] == "project" && len(parts) > 2:
+		switch parts[2] {
+		case "jobs":
+			return "jobs"
+		case "config":
+			return "projects"
+		}
+		return parts[2]
+	case parts[0] == "project":
+		return "projects"
+	}
+	return parts[0]
+}
+
+// InvalidateCache discards all responses cached by the client, so that
+// subsequent reads go to the server. It does nothing if caching is not
+// enabled.
+func (c *Client) InvalidateCache() {
+	c.cache.clear()
+}
@@ -0,0 +1,130 @@
+package rundeck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientCache(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	notModified := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		hits[r.Method+" "+r.URL.Path]++
+
+		w.Header().Set("Content-Type", "application/xml")
+		switch r.URL.Path {
+		case "/api/13/projects":
+			w.Write([]byte(`<projects count="1"><project><name>example</name></project></projects>`))
+		case "/api/13/project/example/jobs", "/api/13/project/other/jobs":
+			w.Write([]byte(`<jobs count="0"></jobs>`))
+		case "/api/13/system/info":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Write([]byte(`<system><rundeck><version>2.6.0</version></rundeck></system>`))
+		case "/api/13/jobs/import":
+			w.Write([]byte(`<result><succeeded count="1"><job index="1"><id>abc</id><name>test</name><group></group><project>example</project></job></succeeded></result>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{
+		BaseURL: server.URL,
+		Cache: &CacheConfig{
+			TTLs: map[string]time.Duration{
+				CacheEndpointProjects:   time.Minute,
+				CacheEndpointJobs:       time.Minute,
+				CacheEndpointSystemInfo: time.Millisecond,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetAllProjects(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetJobSummariesForProject("example"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetJobSummariesForProject("other"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := hits["GET /api/13/projects"]; got != 1 {
+		t.Errorf("projects fetched %d times, but wanted 1", got)
+	}
+	if got := hits["GET /api/13/project/example/jobs"]; got != 1 {
+		t.Errorf("example jobs fetched %d times, but wanted 1", got)
+	}
+
+	// Importing a job invalidates only that project's job list.
+	if _, err := client.CreateOrUpdateJob(&JobDetail{Name: "test", ProjectName: "example"}); err != nil {
+		t.Fatal(err)
+	}
+	client.GetAllProjects()
+	client.GetJobSummariesForProject("example")
+	client.GetJobSummariesForProject("other")
+	if got := hits["GET /api/13/project/example/jobs"]; got != 2 {
+		t.Errorf("example jobs fetched %d times after import, but wanted 2", got)
+	}
+	if got := hits["GET /api/13/project/other/jobs"]; got != 1 {
+		t.Errorf("other jobs fetched %d times after import, but wanted 1", got)
+	}
+	if got := hits["GET /api/13/projects"]; got != 1 {
+		t.Errorf("projects fetched %d times after import, but wanted 1", got)
+	}
+
+	// Stale entries are revalidated using their ETag.
+	for i := 0; i < 2; i++ {
+		info, err := client.GetSystemInfo()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Rundeck.Version != "2.6.0" {
+			t.Errorf("got version %q, but wanted 2.6.0", info.Rundeck.Version)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if notModified != 1 {
+		t.Errorf("got %d revalidations, but wanted 1", notModified)
+	}
+
+	client.InvalidateCache()
+	client.GetAllProjects()
+	if got := hits["GET /api/13/projects"]; got != 2 {
+		t.Errorf("projects fetched %d times after InvalidateCache, but wanted 2", got)
+	}
+}
+
+func TestEndpointFamily(t *testing.T) {
+	tests := map[string]string{
+		"projects":                 "projects",
+		"project/{project}":        "projects",
+		"project/{project}/config": "projects",
+		"project/{project}/jobs":   "jobs",
+		"jobs/export":              "jobs",
+		"jobs/import":              "jobs",
+		"job/{id}":                 "jobs",
+		"storage/keys/{path}":      "storage",
+		"system/info":              "system",
+	}
+	for endpoint, want := range tests {
+		if got := endpointFamily(endpoint); got != want {
+			t.Errorf("endpointFamily(%q) is %q, but wanted %q", endpoint, got, want)
+		}
+	}
+}
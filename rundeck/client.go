@@ -15,7 +15,7 @@ import (
 	"net/http"
 	"net/url"
 	"mime/multipart"
-	"strings"
+	"time"
 )
 
 // ClientConfig is used with NewClient to specify initialization settings.
@@ -29,6 +29,70 @@ type ClientConfig struct {
 	// Don't fail if the server uses SSL with an un-verifiable certificate.
 	// This is not recommended except during development/debugging.
 	AllowUnverifiedSSL bool
+
+	// PEM-encoded certificates of additional certificate authorities to trust
+	// when verifying the server's certificate, for servers whose certificates
+	// are issued by an internal CA.
+	CACertificatesPEM []byte
+
+	// PEM-encoded client certificate and private key to present to the server,
+	// for servers (or proxies in front of them) that require mutual TLS.
+	// Either both or neither must be set.
+	ClientCertificatePEM []byte
+	ClientKeyPEM         []byte
+
+	// TLSConfig, if set, is used as the starting point for the TLS
+	// configuration, before applying the settings above. It is not modified.
+	TLSConfig *tls.Config
+
+	// Proxy selects the proxy to use for each request, with the same meaning
+	// as the field of the same name in http.Transport. Use
+	// http.ProxyFromEnvironment to honor the usual environment variables, or
+	// http.ProxyURL to always use a particular proxy. If nil, no proxy is used.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// Connection pool settings, with the same meaning as the fields of the
+	// same names in http.Transport. Zero values use the http.Transport
+	// defaults.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+
+	// Timeout limits the total time taken by each request, including reading
+	// the response body. Zero means no timeout.
+	Timeout time.Duration
+
+	// Transport, if set, is used to make HTTP requests instead of the default
+	// transport. The TLS, proxy and connection pool settings above have no
+	// effect when Transport is set.
+	Transport http.RoundTripper
+
+	// Hooks are called before and after each API request, for logging and
+	// other instrumentation. See LogHooks for a ready-made logger.
+	Hooks []Hooks
+
+	// Tracer, if set, is used to create a span for each API request.
+	Tracer Tracer
+
+	// Cache, if set, enables caching of responses from read-heavy
+	// endpoints.
+	Cache *CacheConfig
+
+	// RateLimit, if set, limits the rate and concurrency of all requests
+	// made by the client.
+	RateLimit *Limit
+
+	// EndpointLimits sets additional limits for particular classes of
+	// endpoint, such as slower rates for job imports than for reads. A
+	// request must satisfy both its class limit and RateLimit.
+	EndpointLimits map[EndpointClass]Limit
+
+	// Middleware wraps the transport with additional behavior, such as
+	// instrumentation or request signing. Each function is given the
+	// transport built so far and returns a new one, so the last function in
+	// the list is the first to see each request.
+	Middleware []func(http.RoundTripper) http.RoundTripper
 }
 
 // Client is a Rundeck API client interface.
@@ -36,6 +100,10 @@ type Client struct {
 	httpClient *http.Client
 	apiURL     *url.URL
 	authToken  string
+	hooks      []Hooks
+	tracer     Tracer
+	limiters   *limiters
+	cache      *cache
 }
 
 type request struct {
@@ -44,17 +112,24 @@ type request struct {
 	QueryArgs map[string]string
 	Headers map[string]string
 	BodyBytes []byte
+
+	// Class overrides the endpoint class derived from the method and path.
+	Class EndpointClass
+
+	// Project names the project the request relates to, when it can't be
+	// determined from the path or query.
+	Project string
 }
 
 // NewClient returns a configured Rundeck client.
 func NewClient(config *ClientConfig) (*Client, error) {
-	t := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: config.AllowUnverifiedSSL,
-		},
+	t, err := config.transport()
+	if err != nil {
+		return nil, err
 	}
 	httpClient := &http.Client{
 		Transport: t,
+		Timeout:   config.Timeout,
 	}
 
 	apiPath, _ := url.Parse("api/13/")
@@ -68,35 +143,94 @@ func NewClient(config *ClientConfig) (*Client, error) {
 		httpClient: httpClient,
 		apiURL:     apiURL,
 		authToken:  config.AuthToken,
+		hooks:      config.Hooks,
+		tracer:     config.Tracer,
+		limiters:   newLimiters(config),
+		cache:      newCache(config.Cache),
 	}, nil
 }
 
 func (c *Client) rawRequest(req *request) ([]byte, error) {
-	res, err := c.httpClient.Do(req.MakeHTTPRequest(c))
+	httpReq := req.MakeHTTPRequest(c)
+	ev := newRequestEvent(req, httpReq)
+
+	// Fresh cached responses are returned without involving the server, or
+	// the hooks and tracer.
+	cached, fresh := c.cache.lookup(ev)
+	if fresh {
+		return cached.body, nil
+	}
+	if cached != nil {
+		if cached.etag != "" {
+			httpReq.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			httpReq.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	span := c.startSpan(ev)
+	for _, h := range c.hooks {
+		if h.BeforeRequest != nil {
+			h.BeforeRequest(ev)
+		}
+	}
+
+	waitStart := time.Now()
+	release := c.limiters.acquire(req.endpointClass())
+	ev.Wait = time.Since(waitStart)
+
+	start := time.Now()
+	resBodyBytes, err := c.doRawRequest(httpReq, ev)
+	ev.Duration = time.Since(start)
+	release()
+
+	switch {
+	case cached != nil && ev.StatusCode == http.StatusNotModified:
+		c.cache.refresh(cached)
+		resBodyBytes, err = cached.body, nil
+	case err == nil:
+		c.cache.store(ev, resBodyBytes)
+	}
+	if ev.Response != nil {
+		c.cache.invalidate(ev)
+	}
+	ev.Err = err
+
+	for _, h := range c.hooks {
+		if ev.Response != nil && h.AfterResponse != nil {
+			h.AfterResponse(ev)
+		}
+		if err != nil && h.OnError != nil {
+			h.OnError(ev)
+		}
+	}
+	endSpan(span, ev)
+
+	return resBodyBytes, err
+}
+
+func (c *Client) doRawRequest(httpReq *http.Request, ev *RequestEvent) ([]byte, error) {
+	res, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, err
 	}
+	defer res.Body.Close()
 
 	resBodyBytes, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
 	}
-
-	if res.StatusCode == 404 {
-		return nil, &NotFoundError{}
-	}
+	ev.Response = res
+	ev.StatusCode = res.StatusCode
+	ev.ResponseBody = resBodyBytes
 
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		if strings.HasPrefix(res.Header.Get("Content-Type"), "text/xml") {
-			var richErr Error
-			err = xml.Unmarshal(resBodyBytes, &richErr)
-			if err != nil {
-				return nil, fmt.Errorf("HTTP Error %i with error decoding XML body: %s", res.StatusCode, err.Error())
-			}
-			return nil, richErr
+		apiErr := newAPIError(res, resBodyBytes, ev.Method, ev.Path)
+		if res.StatusCode == http.StatusNotFound {
+			return nil, &NotFoundError{apiErr}
 		}
-
-		return nil, fmt.Errorf("HTTP Error %i", res.StatusCode)
+		return nil, apiErr
 	}
 
 	if res.StatusCode != 200 && res.StatusCode != 201 {
@@ -184,20 +318,7 @@ func (c *Client) delete(pathParts []string) error {
 	return c.xmlRequest("DELETE", pathParts, nil, nil, nil)
 }
 
-func (c *Client) postXMLBatch(pathParts []string, args map[string]string, xmlBatch interface{}, result interface{}) error {
-	req := &http.Request{
-		Method: "POST",
-		Header: http.Header{},
-	}
-	req.Header.Add("User-Agent", "Go-Rundeck-API")
-	req.Header.Add("X-Rundeck-Auth-Token", c.authToken)
-
-	urlPath := &url.URL{
-		Path: strings.Join(pathParts, "/"),
-	}
-	reqURL := c.apiURL.ResolveReference(urlPath)
-	req.URL = reqURL
-
+func (c *Client) postXMLBatch(pathParts []string, project string, args map[string]string, xmlBatch interface{}, result interface{}) error {
 	buf := bytes.Buffer{}
 	writer := multipart.NewWriter(&buf)
 	for k, v := range args {
@@ -223,37 +344,24 @@ func (c *Client) postXMLBatch(pathParts []string, args map[string]string, xmlBat
 
 	writer.Close()
 
-	reqBodyReader := bytes.NewReader(buf.Bytes())
-	req.Body = ioutil.NopCloser(reqBodyReader)
-	req.ContentLength = int64(buf.Len())
-	req.Header.Add("Content-Type", writer.FormDataContentType())
-
-	res, err := c.httpClient.Do(req)
-
-	if err != nil {
-		return err
+	req := &request{
+		Method:    "POST",
+		PathParts: pathParts,
+		Headers: map[string]string{
+			"Content-Type": writer.FormDataContentType(),
+		},
+		BodyBytes: buf.Bytes(),
+		Class:     EndpointImport,
+		Project:   project,
 	}
 
-	resBodyBytes, err := ioutil.ReadAll(res.Body)
+	resBodyBytes, err := c.rawRequest(req)
 	if err != nil {
 		return err
 	}
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		if strings.HasPrefix(res.Header.Get("Content-Type"), "text/xml") {
-			var richErr Error
-			err = xml.Unmarshal(resBodyBytes, &richErr)
-			if err != nil {
-				return fmt.Errorf("HTTP Error %i with error decoding XML body: %s", res.StatusCode, err.Error())
-			}
-			return richErr
-		}
-
-		return fmt.Errorf("HTTP Error %i", res.StatusCode)
-	}
-
 	if result != nil {
-		if res.StatusCode != 200 && res.StatusCode != 201 {
+		if resBodyBytes == nil {
 			return fmt.Errorf("server did not return an XML payload")
 		}
 		err = xml.Unmarshal(resBodyBytes, result)
@@ -279,10 +387,7 @@ func (r *request) MakeHTTPRequest(client *Client) *http.Request {
 		req.Header.Add(k, v)
 	}
 
-	urlPath := &url.URL{
-		Path: strings.Join(r.PathParts, "/"),
-	}
-	reqURL := client.apiURL.ResolveReference(urlPath)
+	reqURL := appendURLPath(client.apiURL, r.PathParts)
 	req.URL = reqURL
 
 	if len(r.QueryArgs) > 0 {
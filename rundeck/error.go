@@ -1,9 +1,129 @@
 package rundeck
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for common classes of API failure. Errors returned by
+// Client methods can be tested against these using errors.Is, for example:
+//
+//	if errors.Is(err, rundeck.ErrNotFound) {
+//		// ...
+//	}
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrConflict           = errors.New("conflict")
+	ErrUnsupportedVersion = errors.New("unsupported API version")
+)
+
+// ErrorCodeUnsupportedVersion is the Rundeck error code returned when the
+// server does not support the requested API version.
+const ErrorCodeUnsupportedVersion = "api.error.api-version.unsupported"
+
+// APIError is the error returned by Client methods when the server responds
+// with an error status.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// ErrorCode is the Rundeck error code, such as
+	// "api.error.item.doesnotexist", or empty if the response did not
+	// include one.
+	ErrorCode string
+
+	// APIVersion is the API version reported by the server in the error
+	// response, or empty if the response did not include one.
+	APIVersion string
+
+	// Message is the error message reported by the server, or empty if the
+	// response did not include one.
+	Message string
+
+	// Method and Path describe the request that failed. Path is relative to
+	// the API base URL.
+	Method string
+	Path   string
+
+	// Body is the raw body of the response.
+	Body []byte
+}
+
+func (err *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: HTTP %d", err.Method, err.Path, err.StatusCode)
+	if text := http.StatusText(err.StatusCode); text != "" {
+		msg += " " + text
+	}
+	if err.Message != "" {
+		msg += ": " + err.Message
+	}
+	if err.ErrorCode != "" {
+		msg += " (" + err.ErrorCode + ")"
+	}
+	return msg
+}
+
+// Is allows an APIError to be matched against the sentinel errors using
+// errors.Is.
+func (err *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return err.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return err.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return err.StatusCode == http.StatusForbidden
+	case ErrConflict:
+		return err.StatusCode == http.StatusConflict
+	case ErrUnsupportedVersion:
+		return err.ErrorCode == ErrorCodeUnsupportedVersion
+	}
+	return false
+}
+
+// newAPIError builds an APIError from an error response, decoding the
+// Rundeck error details from the body if it is XML.
+func newAPIError(res *http.Response, body []byte, method, path string) *APIError {
+	apiErr := &APIError{
+		StatusCode: res.StatusCode,
+		Method:     method,
+		Path:       path,
+		Body:       body,
+	}
+	if strings.Contains(res.Header.Get("Content-Type"), "xml") {
+		var result errorResult
+		if xml.Unmarshal(body, &result) == nil {
+			apiErr.ErrorCode = result.Error.Code
+			apiErr.APIVersion = result.APIVersion
+			apiErr.Message = result.Error.Message
+		}
+	}
+	return apiErr
+}
+
+// errorResult is the XML representation of an error returned from the
+// server.
+type errorResult struct {
+	XMLName    xml.Name `xml:"result"`
+	IsError    bool     `xml:"error,attr"`
+	APIVersion string   `xml:"apiversion,attr"`
+	Error      struct {
+		Code    string `xml:"code,attr"`
+		Message string `xml:"message"`
+	} `xml:"error"`
+}
 
 // Error implements the error interface for a Rundeck API error that was
 // returned from the server as XML.
+//
+// Deprecated: Client methods no longer return this type; they return
+// *APIError, which includes the message along with the error code, HTTP
+// status and request.
 type Error struct {
 	XMLName xml.Name `xml:"result"`
 	IsError bool `xml:"error,attr"`
@@ -14,8 +134,43 @@ func (err Error) Error() string {
 	return err.Message
 }
 
-type NotFoundError struct {}
+// NotFoundError is returned by Client methods when the server responds with
+// HTTP 404. It wraps the *APIError describing the response, so it can be
+// matched with errors.Is(err, ErrNotFound) and errors.As as well as with a
+// type assertion.
+type NotFoundError struct {
+	*APIError
+}
 
 func (err NotFoundError) Error() string {
-	return "not found"
+	if err.APIError == nil {
+		return "not found"
+	}
+	return err.APIError.Error()
+}
+
+// Is allows a NotFoundError to be matched against ErrNotFound.
+func (err NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the APIError describing the response, if any.
+func (err NotFoundError) Unwrap() error {
+	if err.APIError == nil {
+		return nil
+	}
+	return err.APIError
+}
+
+// JobValidationError is returned by JobDetail.Validate to describe all of the
+// problems found with a job definition.
+type JobValidationError struct {
+	Problems []string
+}
+
+func (err *JobValidationError) Error() string {
+	if len(err.Problems) == 1 {
+		return "invalid job: " + err.Problems[0]
+	}
+	return fmt.Sprintf("invalid job (%d problems): %s", len(err.Problems), strings.Join(err.Problems, "; "))
 }
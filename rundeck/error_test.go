@@ -0,0 +1,160 @@
+package rundeck
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Status      int
+		ContentType string
+		Body        string
+		Want        APIError
+		Is          error
+	}{
+		{
+			Name:        "not found",
+			Status:      http.StatusNotFound,
+			ContentType: "text/xml;charset=UTF-8",
+			Body:        `<result error="true" apiversion="13"><error code="api.error.item.doesnotexist"><message>Job ID does not exist: abc</message></error></result>`,
+			Want: APIError{
+				StatusCode: http.StatusNotFound,
+				ErrorCode:  "api.error.item.doesnotexist",
+				APIVersion: "13",
+				Message:    "Job ID does not exist: abc",
+			},
+			Is: ErrNotFound,
+		},
+		{
+			Name:        "unsupported version",
+			Status:      http.StatusBadRequest,
+			ContentType: "application/xml",
+			Body:        `<result error="true" apiversion="11"><error code="api.error.api-version.unsupported"><message>Unsupported API Version "13"</message></error></result>`,
+			Want: APIError{
+				StatusCode: http.StatusBadRequest,
+				ErrorCode:  ErrorCodeUnsupportedVersion,
+				APIVersion: "11",
+				Message:    `Unsupported API Version "13"`,
+			},
+			Is: ErrUnsupportedVersion,
+		},
+		{
+			Name:        "conflict",
+			Status:      http.StatusConflict,
+			ContentType: "text/xml",
+			Body:        `<result error="true"><error><message>already exists</message></error></result>`,
+			Want: APIError{
+				StatusCode: http.StatusConflict,
+				Message:    "already exists",
+			},
+			Is: ErrConflict,
+		},
+		{
+			Name:        "unauthorized without XML",
+			Status:      http.StatusUnauthorized,
+			ContentType: "text/html",
+			Body:        `<html>login required</html>`,
+			Want: APIError{
+				StatusCode: http.StatusUnauthorized,
+			},
+			Is: ErrUnauthorized,
+		},
+		{
+			Name:        "forbidden with invalid XML",
+			Status:      http.StatusForbidden,
+			ContentType: "text/xml",
+			Body:        `<result`,
+			Want: APIError{
+				StatusCode: http.StatusForbidden,
+			},
+			Is: ErrForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", test.ContentType)
+				w.WriteHeader(test.Status)
+				w.Write([]byte(test.Body))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(&ClientConfig{BaseURL: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.GetJob("abc")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got %#v, but wanted *APIError", err)
+			}
+			if apiErr.StatusCode != test.Want.StatusCode {
+				t.Errorf("got StatusCode %d, but wanted %d", apiErr.StatusCode, test.Want.StatusCode)
+			}
+			if apiErr.ErrorCode != test.Want.ErrorCode {
+				t.Errorf("got ErrorCode %q, but wanted %q", apiErr.ErrorCode, test.Want.ErrorCode)
+			}
+			if apiErr.APIVersion != test.Want.APIVersion {
+				t.Errorf("got APIVersion %q, but wanted %q", apiErr.APIVersion, test.Want.APIVersion)
+			}
+			if apiErr.Message != test.Want.Message {
+				t.Errorf("got Message %q, but wanted %q", apiErr.Message, test.Want.Message)
+			}
+			if apiErr.Method != "GET" || apiErr.Path != "job/abc" {
+				t.Errorf("got request %s %s, but wanted GET job/abc", apiErr.Method, apiErr.Path)
+			}
+			if string(apiErr.Body) != test.Body {
+				t.Errorf("got Body %q, but wanted %q", apiErr.Body, test.Body)
+			}
+
+			for _, sentinel := range []error{ErrNotFound, ErrUnauthorized, ErrForbidden, ErrConflict, ErrUnsupportedVersion} {
+				if got, want := errors.Is(err, sentinel), sentinel == test.Is; got != want {
+					t.Errorf("errors.Is(err, %q) is %t, but wanted %t", sentinel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNotFoundErrorIs(t *testing.T) {
+	if !errors.Is(&NotFoundError{}, ErrNotFound) {
+		t.Errorf("NotFoundError does not match ErrNotFound")
+	}
+	if errors.As(&NotFoundError{}, new(*APIError)) {
+		t.Errorf("NotFoundError without an APIError unwraps to one")
+	}
+}
+
+func TestNotFoundErrorTypeAssertion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`<result error="true" apiversion="13"><error code="api.error.item.doesnotexist"><message>Job ID does not exist: abc</message></error></result>`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Callers written before APIError was added detect missing resources
+	// with a type assertion.
+	_, err = client.GetJob("abc")
+	notFound, ok := err.(*NotFoundError)
+	if !ok {
+		t.Fatalf("got %#v, but wanted *NotFoundError", err)
+	}
+	if notFound.StatusCode != http.StatusNotFound || notFound.ErrorCode != "api.error.item.doesnotexist" {
+		t.Errorf("got %#v, but wanted the 404 response details", notFound.APIError)
+	}
+	if want := "GET job/abc: HTTP 404 Not Found: Job ID does not exist: abc (api.error.item.doesnotexist)"; err.Error() != want {
+		t.Errorf("got message %q, but wanted %q", err.Error(), want)
+	}
+}
@@ -0,0 +1,229 @@
+package rundeck
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RequestEvent describes an API request as it passes through the client, for
+// use by Hooks.
+type RequestEvent struct {
+	// Method is the HTTP method of the request.
+	Method string
+
+	// Endpoint identifies the API endpoint being called, with the variable
+	// parts of the path replaced by placeholders, such as
+	// "project/{project}/jobs". Unlike Path, it is suitable for grouping
+	// requests in metrics.
+	Endpoint string
+
+	// Path is the path of the request relative to the API base URL.
+	Path string
+
+	// Project is the name of the project the request relates to, or empty
+	// if it does not relate to a single project.
+	Project string
+
+	// Request is the HTTP request that will be or was sent. BeforeRequest
+	// hooks may modify its headers. It contains the auth token, so care must
+	// be taken not to log it verbatim.
+	Request *http.Request
+
+	// RequestBody is the body of the request, or nil if it has none.
+	RequestBody []byte
+
+	// Response is the HTTP response, or nil if no response was received. Its
+	// body has already been read into ResponseBody.
+	Response *http.Response
+
+	// StatusCode is the HTTP status code of the response, or zero if no
+	// response was received.
+	StatusCode int
+
+	// ResponseBody is the body of the response, or nil if no response was
+	// received.
+	ResponseBody []byte
+
+	// Wait is the time spent waiting for the client's rate and concurrency
+	// limits before the request was sent.
+	Wait time.Duration
+
+	// Duration is the time taken to send the request and read the response.
+	Duration time.Duration
+
+	// Err is the error that the API call will return, if any.
+	Err error
+}
+
+// Hooks are functions that the client calls at points in the life of each
+// API request. Any of the functions may be nil.
+type Hooks struct {
+	// BeforeRequest is called before the request is sent.
+	BeforeRequest func(ev *RequestEvent)
+
+	// AfterResponse is called when a response is received, even if its status
+	// code indicates an error.
+	AfterResponse func(ev *RequestEvent)
+
+	// OnError is called when the request fails, whether because no response
+	// was received or because the response indicates an error. For error
+	// responses it is called after AfterResponse.
+	OnError func(ev *RequestEvent)
+}
+
+// Logger is the interface used by LogHooks to write log lines. *log.Logger
+// implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// LogHooks returns hooks that write a line of space-separated key=value pairs
+// to the given logger for each request and response.
+//
+// If logBodies is set, the request and response bodies are included too.
+// In all cases the auth token is redacted, as is the content of keys sent to
+// or retrieved from key storage.
+func LogHooks(logger Logger, logBodies bool) Hooks {
+	return Hooks{
+		BeforeRequest: func(ev *RequestEvent) {
+			fields := ev.logFields()
+			fields = append(fields, "headers", formatHeaders(ev.Request.Header))
+			if logBodies && ev.RequestBody != nil {
+				fields = append(fields, "body", ev.loggableBody(ev.RequestBody, ev.Request.Header))
+			}
+			logger.Printf("rundeck request %s", formatLogFields(fields))
+		},
+		AfterResponse: func(ev *RequestEvent) {
+			fields := ev.logFields()
+			fields = append(fields,
+				"status", strconv.Itoa(ev.StatusCode),
+				"duration", ev.Duration.String(),
+			)
+			if ev.Wait > 0 {
+				fields = append(fields, "wait", ev.Wait.String())
+			}
+			if logBodies {
+				fields = append(fields, "body", ev.loggableBody(ev.ResponseBody, ev.Response.Header))
+			}
+			logger.Printf("rundeck response %s", formatLogFields(fields))
+		},
+		OnError: func(ev *RequestEvent) {
+			fields := ev.logFields()
+			if ev.StatusCode != 0 {
+				fields = append(fields, "status", strconv.Itoa(ev.StatusCode))
+			}
+			fields = append(fields, "error", ev.Err.Error())
+			logger.Printf("rundeck error %s", formatLogFields(fields))
+		},
+	}
+}
+
+// redacted replaces secret values in log output.
+const redacted = "[REDACTED]"
+
+func newRequestEvent(req *request, httpReq *http.Request) *RequestEvent {
+	endpoint, project := endpointForPath(req.PathParts)
+	if project == "" {
+		project = req.Project
+	}
+	if project == "" && req.QueryArgs != nil {
+		project = req.QueryArgs["project"]
+	}
+	return &RequestEvent{
+		Method:      req.Method,
+		Endpoint:    endpoint,
+		Path:        strings.Join(req.PathParts, "/"),
+		Project:     project,
+		Request:     httpReq,
+		RequestBody: req.BodyBytes,
+	}
+}
+
+// endpointForPath replaces the variable parts of an API path with
+// placeholders, returning the result along with the project name if the
+// path contains one.
+func endpointForPath(parts []string) (string, string) {
+	if len(parts) == 0 {
+		return "", ""
+	}
+	endpoint := make([]string, len(parts))
+	copy(endpoint, parts)
+	project := ""
+
+	switch parts[0] {
+	case "project":
+		if len(parts) > 1 {
+			project = parts[1]
+			endpoint[1] = "{project}"
+		}
+		if len(parts) > 3 && parts[2] == "config" {
+			endpoint = append(endpoint[:3], "{key}")
+		}
+	case "job", "execution":
+		if len(parts) > 1 {
+			endpoint[1] = "{id}"
+		}
+	case "storage":
+		if len(parts) > 2 {
+			endpoint = append(endpoint[:2], "{path}")
+		}
+	}
+	return strings.Join(endpoint, "/"), project
+}
+
+func (ev *RequestEvent) logFields() []string {
+	fields := []string{
+		"method", ev.Method,
+		"endpoint", ev.Endpoint,
+		"path", ev.Path,
+	}
+	if ev.Project != "" {
+		fields = append(fields, "project", ev.Project)
+	}
+	return fields
+}
+
+// loggableBody returns the body as a string, unless it is key content.
+// Key metadata is returned as XML, so only other content types are secret.
+func (ev *RequestEvent) loggableBody(body []byte, header http.Header) string {
+	if strings.HasPrefix(ev.Path, "storage/") && !strings.Contains(header.Get("Content-Type"), "xml") && len(body) > 0 {
+		return redacted
+	}
+	return string(body)
+}
+
+func formatHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ",")
+		if http.CanonicalHeaderKey(name) == "X-Rundeck-Auth-Token" {
+			value = redacted
+		}
+		parts = append(parts, name+": "+value)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatLogFields renders alternating keys and values as key=value pairs,
+// quoting values that would otherwise be ambiguous.
+func formatLogFields(fields []string) string {
+	parts := make([]string, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		value := fields[i+1]
+		if value == "" || strings.ContainsAny(value, " \t\r\n\"=") {
+			value = strconv.Quote(value)
+		}
+		parts = append(parts, fmt.Sprintf("%s=%s", fields[i], value))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,115 @@
+package rundeck
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Err        error
+	Ended      bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.Attributes[key] = value
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.Err = err
+}
+
+func (s *testSpan) End() {
+	s.Ended = true
+}
+
+type testTracer struct {
+	Spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(name string, attributes map[string]interface{}) Span {
+	span := &testSpan{
+		Name:       name,
+		Attributes: attributes,
+	}
+	t.Spans = append(t.Spans, span)
+	return span
+}
+
+func TestClientHooks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/jobs") {
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<jobs count="0"></jobs>`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logBuf := &bytes.Buffer{}
+	tracer := &testTracer{}
+	errorCount := 0
+	client, err := NewClient(&ClientConfig{
+		BaseURL:   server.URL,
+		AuthToken: "secret-token",
+		Hooks: []Hooks{
+			LogHooks(log.New(logBuf, "", 0), true),
+			Hooks{
+				OnError: func(ev *RequestEvent) {
+					errorCount++
+				},
+			},
+		},
+		Tracer: tracer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.GetJobSummariesForProject("example"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := client.CreatePassword("db/password", "hunter2"); err == nil {
+		t.Fatalf("creating password succeeded; want error")
+	}
+
+	logStr := logBuf.String()
+	for _, secret := range []string{"secret-token", "hunter2"} {
+		if strings.Contains(logStr, secret) {
+			t.Errorf("log contains secret %q:\n%s", secret, logStr)
+		}
+	}
+	for _, want := range []string{
+		"rundeck request method=GET endpoint=project/{project}/jobs path=project/example/jobs project=example",
+		"rundeck response method=GET endpoint=project/{project}/jobs path=project/example/jobs project=example status=200",
+		"rundeck error method=POST endpoint=storage/keys/{path} path=storage/keys/db/password status=500",
+	} {
+		if !strings.Contains(logStr, want) {
+			t.Errorf("log does not contain %q:\n%s", want, logStr)
+		}
+	}
+	if errorCount != 1 {
+		t.Errorf("OnError called %d times, but wanted 1", errorCount)
+	}
+
+	if len(tracer.Spans) != 2 {
+		t.Fatalf("got %d spans, but wanted 2", len(tracer.Spans))
+	}
+	span := tracer.Spans[0]
+	if span.Name != "rundeck GET project/{project}/jobs" || !span.Ended || span.Err != nil {
+		t.Errorf("got first span %#v", span)
+	}
+	if span.Attributes[SpanAttrProject] != "example" || span.Attributes[SpanAttrStatusCode] != 200 {
+		t.Errorf("got first span attributes %#v", span.Attributes)
+	}
+	span = tracer.Spans[1]
+	if span.Err == nil || span.Attributes[SpanAttrStatusCode] != 500 {
+		t.Errorf("got second span %#v", span)
+	}
+}
@@ -3,6 +3,7 @@ package rundeck
 import (
 	"encoding/xml"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -48,6 +49,13 @@ type JobDetail struct {
 	NodesSelectedByDefault *Boolean     `xml:"nodesSelectedByDefault"`
 	Schedule               *JobSchedule `xml:"schedule,omitempty"`
 	ScheduleEnabled        bool         `xml:"scheduleEnabled"`
+
+	// Elements and attributes that this package does not model, such as those
+	// added in newer versions of Rundeck or by plugins. These are preserved so
+	// that a job retrieved with GetJob can be passed to CreateOrUpdateJob
+	// without losing any of its configuration.
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
 }
 
 type Boolean struct {
@@ -55,27 +63,36 @@ type Boolean struct {
 }
 
 type JobNotification struct {
-	OnFailure *Notification `xml:"onfailure,omitempty"`
-	OnStart   *Notification `xml:"onstart,omitempty"`
-	OnSuccess *Notification `xml:"onsuccess,omitempty"`
+	OnFailure     *Notification `xml:"onfailure,omitempty"`
+	OnStart       *Notification `xml:"onstart,omitempty"`
+	OnSuccess     *Notification `xml:"onsuccess,omitempty"`
+	ExtraElements []XMLElement  `xml:",any"`
 }
 
 type Notification struct {
 	Email   *EmailNotification   `xml:"email,omitempty"`
 	WebHook *WebHookNotification `xml:"webhook,omitempty"`
 	Plugin  *JobPlugin           `xml:"plugin"`
+
+	// Rundeck allows several notification plugins for each trigger. The first
+	// is in Plugin and any others are kept here, in document order.
+	ExtraPlugins []JobPlugin `xml:"-"`
+
+	ExtraElements []XMLElement `xml:",any"`
 }
 
 type EmailNotification struct {
 	AttachLog  bool               `xml:"attachLog,attr,omitempty"`
 	Recipients NotificationEmails `xml:"recipients,attr"`
 	Subject    string             `xml:"subject,attr"`
+	ExtraAttrs []xml.Attr         `xml:",any,attr"`
 }
 
 type NotificationEmails []string
 
 type WebHookNotification struct {
-	Urls NotificationUrls `xml:"urls,attr"`
+	Urls       NotificationUrls `xml:"urls,attr"`
+	ExtraAttrs []xml.Attr       `xml:",any,attr"`
 }
 
 type NotificationUrls []string
@@ -87,6 +104,14 @@ type JobSchedule struct {
 	Month      JobScheduleMonth       `xml:"month"`
 	WeekDay    *JobScheduleWeekDay    `xml:"weekday,omitempty"`
 	Year       JobScheduleYear        `xml:"year"`
+
+	// A Quartz cron expression, used by Rundeck as an alternative to the other
+	// schedule fields. When set, any of Time, Month and Year that are empty are
+	// omitted from the XML.
+	Crontab string `xml:"crontab,attr,omitempty"`
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
 }
 
 type JobScheduleDayOfMonth struct {
@@ -123,8 +148,10 @@ type jobDetailList struct {
 
 // JobOptions represents the set of options on a job, if any.
 type JobOptions struct {
-	PreserveOrder bool        `xml:"preserveOrder,attr,omitempty"`
-	Options       []JobOption `xml:"option"`
+	PreserveOrder bool         `xml:"preserveOrder,attr,omitempty"`
+	Options       []JobOption  `xml:"option"`
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
 }
 
 // JobOption represents a single option on a job.
@@ -174,6 +201,9 @@ type JobOption struct {
 
 	// Description of the value to be shown in the Rundeck UI.
 	Description string `xml:"description,omitempty"`
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
 }
 
 // JobValueChoices is a specialization of []string representing a sequence of predefined values
@@ -187,8 +217,9 @@ type JobCommandSequence struct {
 	// If set, Rundeck will continue with subsequent commands after a command fails.
 	ContinueOnError bool `xml:"keepgoing,attr"`
 
-	// Chooses the strategy by which Rundeck will execute commands. Can either be "node-first" or
-	// "step-first".
+	// Chooses the strategy by which Rundeck will execute commands. The built-in strategies are
+	// "node-first", "step-first" and "parallel". Any other value names a workflow strategy
+	// plugin, such as "ruleset", whose configuration is given in PluginConfig.
 	OrderingStrategy string `xml:"strategy,attr,omitempty"`
 
 	// Sequence of commands to run in the sequence.
@@ -196,8 +227,32 @@ type JobCommandSequence struct {
 
 	// Description
 	Description string `xml:"description,omitempty"`
+
+	// Configuration for plugins that apply to the sequence as a whole.
+	PluginConfig *JobWorkflowPluginConfig `xml:"pluginConfig,omitempty"`
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
 }
 
+// JobWorkflowPluginConfig describes the configuration of plugins that apply to a whole
+// command sequence.
+type JobWorkflowPluginConfig struct {
+	// Configuration for workflow strategy plugins, keyed by strategy name. Only the
+	// entry for the sequence's OrderingStrategy is used by Rundeck, but any others
+	// are preserved.
+	WorkflowStrategy JobWorkflowStrategyConfig `xml:"WorkflowStrategy,omitempty"`
+
+	// Log filter plugins to apply to the output of every command in the sequence.
+	LogFilters []JobLogFilter `xml:"LogFilter"`
+
+	ExtraElements []XMLElement `xml:",any"`
+}
+
+// JobWorkflowStrategyConfig is a specialization of map[string]JobPluginConfig that maps
+// workflow strategy names to their configuration.
+type JobWorkflowStrategyConfig map[string]JobPluginConfig
+
 // JobCommand describes a particular command to run within the sequence of commands on a job.
 // The members of this struct are mutually-exclusive except for the pair of ScriptFile and
 // ScriptFileArgs.
@@ -240,13 +295,43 @@ type JobCommand struct {
 
 	// Configuration for a node step plugin to run as this command.
 	NodeStepPlugin *JobPlugin `xml:"node-step-plugin"`
+
+	// Plugins that modify the behavior of this command, such as log filters.
+	Plugins *JobCommandPlugins `xml:"plugins,omitempty"`
+
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
+}
+
+// JobCommandPlugins describes the plugins that apply to a single command.
+type JobCommandPlugins struct {
+	// Log filter plugins to apply to the output of the command.
+	LogFilters []JobLogFilter `xml:"LogFilter"`
+
+	ExtraElements []XMLElement `xml:",any"`
+}
+
+// JobLogFilter is the configuration for a log filter plugin, such as "key-value-data",
+// "mask-passwords" or "highlight-output", applied to a command or a whole sequence.
+type JobLogFilter struct {
+	XMLName       xml.Name           `xml:"LogFilter"`
+	Type          string             `xml:"type,attr"`
+	Config        JobLogFilterConfig `xml:"config,omitempty"`
+	ExtraAttrs    []xml.Attr         `xml:",any,attr"`
+	ExtraElements []XMLElement       `xml:",any"`
 }
 
+// JobLogFilterConfig is a specialization of map[string]string for log filter plugin
+// configuration. Unlike JobPluginConfig, each entry is represented in XML as an element
+// named after its key.
+type JobLogFilterConfig map[string]string
+
 // (Inline) Script interpreter
 type JobCommandScriptInterpreter struct {
-	XMLName          xml.Name `xml:"scriptinterpreter"`
-	InvocationString string   `xml:",chardata"`
-	ArgsQuoted       bool     `xml:"argsquoted,attr,omitempty"`
+	XMLName          xml.Name   `xml:"scriptinterpreter"`
+	InvocationString string     `xml:",chardata"`
+	ArgsQuoted       bool       `xml:"argsquoted,attr,omitempty"`
+	ExtraAttrs       []xml.Attr `xml:",any,attr"`
 }
 
 // JobCommandJobRef is a reference to another job that will run as one of the commands of a job.
@@ -258,6 +343,11 @@ type JobCommandJobRef struct {
 	Dispatch       *JobDispatch              `xml:"dispatch,omitempty"`
 	NodeFilter     *JobNodeFilter            `xml:"nodefilters,omitempty"`
 	Arguments      JobCommandJobRefArguments `xml:"arg"`
+	ExtraAttrs     []xml.Attr                `xml:",any,attr"`
+	ExtraElements  []XMLElement              `xml:",any"`
+
+	// Attributes of the arg element other than the argument line.
+	ExtraArgAttrs []xml.Attr `xml:"-"`
 }
 
 // JobCommandJobRefArguments is a string representing the arguments in a JobCommandJobRef.
@@ -265,9 +355,11 @@ type JobCommandJobRefArguments string
 
 // Plugin is a configuration for a plugin to run within a job or notification.
 type JobPlugin struct {
-	XMLName xml.Name
-	Type    string          `xml:"type,attr"`
-	Config  JobPluginConfig `xml:"configuration"`
+	XMLName       xml.Name
+	Type          string          `xml:"type,attr"`
+	Config        JobPluginConfig `xml:"configuration"`
+	ExtraAttrs    []xml.Attr      `xml:",any,attr"`
+	ExtraElements []XMLElement    `xml:",any"`
 }
 
 // JobPluginConfig is a specialization of map[string]string for job plugin configuration.
@@ -276,7 +368,9 @@ type JobPluginConfig map[string]string
 // JobNodeFilter describes which nodes from the project's resource list will run the configured
 // commands.
 type JobNodeFilter struct {
-	Query string `xml:"filter,omitempty"`
+	Query         string       `xml:"filter,omitempty"`
+	ExtraAttrs    []xml.Attr   `xml:",any,attr"`
+	ExtraElements []XMLElement `xml:",any"`
 }
 
 type jobImportResults struct {
@@ -299,11 +393,12 @@ type jobImportResult struct {
 }
 
 type JobDispatch struct {
-	ExcludePrecedence *Boolean `xml:"excludePrecedence"`
-	MaxThreadCount    int      `xml:"threadcount,omitempty"`
-	ContinueOnError   bool     `xml:"keepgoing"`
-	RankAttribute     string   `xml:"rankAttribute,omitempty"`
-	RankOrder         string   `xml:"rankOrder,omitempty"`
+	ExcludePrecedence *Boolean     `xml:"excludePrecedence"`
+	MaxThreadCount    int          `xml:"threadcount,omitempty"`
+	ContinueOnError   bool         `xml:"keepgoing"`
+	RankAttribute     string       `xml:"rankAttribute,omitempty"`
+	RankOrder         string       `xml:"rankOrder,omitempty"`
+	ExtraElements     []XMLElement `xml:",any"`
 }
 
 // GetJobSummariesForProject returns summaries of the jobs belonging to the named project.
@@ -354,7 +449,7 @@ func (c *Client) importJob(job *JobDetail, dupeOption string) (*JobSummary, erro
 		"uuidOption": "preserve",
 	}
 	result := &jobImportResults{}
-	err := c.postXMLBatch([]string{"jobs", "import"}, args, jobList, result)
+	err := c.postXMLBatch([]string{"jobs", "import"}, job.ProjectName, args, jobList, result)
 	if err != nil {
 		return nil, err
 	}
@@ -378,6 +473,41 @@ func (c *Client) DeleteJob(id string) error {
 	return c.delete([]string{"job", id})
 }
 
+func (s JobSchedule) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type jobScheduleXML struct {
+		DayOfMonth    *JobScheduleDayOfMonth `xml:"dayofmonth,omitempty"`
+		Time          *JobScheduleTime       `xml:"time"`
+		Month         *JobScheduleMonth      `xml:"month"`
+		WeekDay       *JobScheduleWeekDay    `xml:"weekday,omitempty"`
+		Year          *JobScheduleYear       `xml:"year"`
+		Crontab       string                 `xml:"crontab,attr,omitempty"`
+		ExtraAttrs    []xml.Attr             `xml:",any,attr"`
+		ExtraElements []XMLElement           `xml:",any"`
+	}
+	r := jobScheduleXML{
+		DayOfMonth:    s.DayOfMonth,
+		Time:          &s.Time,
+		Month:         &s.Month,
+		WeekDay:       s.WeekDay,
+		Year:          &s.Year,
+		Crontab:       s.Crontab,
+		ExtraAttrs:    s.ExtraAttrs,
+		ExtraElements: s.ExtraElements,
+	}
+	if s.Crontab != "" {
+		if s.Time.Hour == "" && s.Time.Minute == "" && s.Time.Seconds == "" {
+			r.Time = nil
+		}
+		if s.Month.Day == "" && s.Month.Month == "" {
+			r.Month = nil
+		}
+		if s.Year.Year == "" {
+			r.Year = nil
+		}
+	}
+	return e.EncodeElement(r, start)
+}
+
 func (c NotificationEmails) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
 	if len(c) > 0 {
 		return xml.Attr{name, strings.Join(c, ",")}, nil
@@ -441,6 +571,100 @@ func (a *JobCommandJobRefArguments) UnmarshalXML(d *xml.Decoder, start xml.Start
 	return nil
 }
 
+// notificationXML is the XML form of a Notification, in which all of the
+// plugins are in a single list.
+type notificationXML struct {
+	Email         *EmailNotification   `xml:"email,omitempty"`
+	WebHook       *WebHookNotification `xml:"webhook,omitempty"`
+	Plugins       []JobPlugin          `xml:"plugin"`
+	ExtraElements []XMLElement         `xml:",any"`
+}
+
+func (n Notification) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	x := notificationXML{
+		Email:         n.Email,
+		WebHook:       n.WebHook,
+		ExtraElements: n.ExtraElements,
+	}
+	if n.Plugin != nil {
+		x.Plugins = append([]JobPlugin{*n.Plugin}, n.ExtraPlugins...)
+	}
+	return e.EncodeElement(x, start)
+}
+
+func (n *Notification) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	x := notificationXML{}
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+	*n = Notification{
+		Email:         x.Email,
+		WebHook:       x.WebHook,
+		ExtraElements: x.ExtraElements,
+	}
+	if len(x.Plugins) > 0 {
+		n.Plugin = &x.Plugins[0]
+		n.ExtraPlugins = x.Plugins[1:]
+	}
+	return nil
+}
+
+// jobRefXML is the XML form of a JobCommandJobRef, in which the arg element
+// keeps its other attributes.
+type jobRefXML struct {
+	XMLName        xml.Name       `xml:"jobref"`
+	Name           string         `xml:"name,attr"`
+	GroupName      string         `xml:"group,attr"`
+	RunForEachNode bool           `xml:"nodeStep,attr"`
+	Dispatch       *JobDispatch   `xml:"dispatch,omitempty"`
+	NodeFilter     *JobNodeFilter `xml:"nodefilters,omitempty"`
+	Arguments      jobRefArgsXML  `xml:"arg"`
+	ExtraAttrs     []xml.Attr     `xml:",any,attr"`
+	ExtraElements  []XMLElement   `xml:",any"`
+}
+
+type jobRefArgsXML struct {
+	Line       string     `xml:"line,attr"`
+	ExtraAttrs []xml.Attr `xml:",any,attr"`
+}
+
+func (r JobCommandJobRef) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	x := jobRefXML{
+		Name:           r.Name,
+		GroupName:      r.GroupName,
+		RunForEachNode: r.RunForEachNode,
+		Dispatch:       r.Dispatch,
+		NodeFilter:     r.NodeFilter,
+		Arguments: jobRefArgsXML{
+			Line:       string(r.Arguments),
+			ExtraAttrs: r.ExtraArgAttrs,
+		},
+		ExtraAttrs:    r.ExtraAttrs,
+		ExtraElements: r.ExtraElements,
+	}
+	return e.EncodeElement(x, start)
+}
+
+func (r *JobCommandJobRef) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	x := jobRefXML{}
+	if err := d.DecodeElement(&x, &start); err != nil {
+		return err
+	}
+	*r = JobCommandJobRef{
+		XMLName:        x.XMLName,
+		Name:           x.Name,
+		GroupName:      x.GroupName,
+		RunForEachNode: x.RunForEachNode,
+		Dispatch:       x.Dispatch,
+		NodeFilter:     x.NodeFilter,
+		Arguments:      JobCommandJobRefArguments(x.Arguments.Line),
+		ExtraAttrs:     x.ExtraAttrs,
+		ExtraElements:  x.ExtraElements,
+		ExtraArgAttrs:  x.Arguments.ExtraAttrs,
+	}
+	return nil
+}
+
 func (c JobPluginConfig) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	rc := map[string]string(c)
 	return marshalMapToXML(&rc, e, start, "entry", "key", "value")
@@ -451,6 +675,75 @@ func (c *JobPluginConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) e
 	return unmarshalMapFromXML(rc, d, start, "entry", "key", "value")
 }
 
+func (c JobLogFilterConfig) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(c) == 0 {
+		return nil
+	}
+	rc := map[string]string(c)
+	return marshalMapToXMLElements(&rc, e, start)
+}
+
+func (c *JobLogFilterConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	rc := (*map[string]string)(c)
+	return unmarshalMapFromXMLElements(rc, d, start)
+}
+
+func (c JobWorkflowStrategyConfig) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(c) == 0 {
+		return nil
+	}
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	// Sort the strategy names so we'll have a deterministic result.
+	names := []string{}
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rc := map[string]string(c[name])
+		err = marshalMapToXMLElements(&rc, e, xml.StartElement{Name: xml.Name{Local: name}})
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+func (c *JobWorkflowStrategyConfig) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	result := JobWorkflowStrategyConfig{}
+	for {
+		token, err := d.Token()
+		if token == nil {
+			err = fmt.Errorf("EOF while decoding workflow strategy config")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		default:
+			continue
+		case xml.StartElement:
+			var rc map[string]string
+			err = unmarshalMapFromXMLElements(&rc, d, t)
+			if err != nil {
+				return err
+			}
+			result[t.Name.Local] = JobPluginConfig(rc)
+		case xml.EndElement:
+			if t.Name.Local == start.Name.Local {
+				*c = result
+				return nil
+			}
+		}
+	}
+}
+
 // JobSummary produces a JobSummary instance with values populated from the import result.
 // The summary object won't have its Description populated, since import results do not
 // include descriptions.
@@ -1,7 +1,13 @@
 package rundeck
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -112,6 +118,29 @@ func TestUnmarshalJobPlugin(t *testing.T) {
 	})
 }
 
+func TestUnmarshalNotificationPlugins(t *testing.T) {
+	testUnmarshalXML(t, []unmarshalTest{
+		unmarshalTest{
+			"two-plugins",
+			`<onsuccess><plugin type="a"><configuration><entry key="k" value="1"/></configuration></plugin><plugin type="b"/></onsuccess>`,
+			&Notification{},
+			func(rv interface{}) error {
+				v := rv.(*Notification)
+				if v.Plugin == nil || v.Plugin.Type != "a" || v.Plugin.Config["k"] != "1" {
+					return fmt.Errorf("got Plugin %#v, but expecting type a with k=1", v.Plugin)
+				}
+				if len(v.ExtraPlugins) != 1 {
+					return fmt.Errorf("got %d ExtraPlugins, but expecting 1", len(v.ExtraPlugins))
+				}
+				if p := v.ExtraPlugins[0]; p.Type != "b" || len(p.Config) != 0 {
+					return fmt.Errorf("got ExtraPlugins[0] %#v, but expecting type b with no config", p)
+				}
+				return nil
+			},
+		},
+	})
+}
+
 func TestMarshalJobCommand(t *testing.T) {
 	testMarshalXML(t, []marshalTest{
 		marshalTest{
@@ -312,3 +341,253 @@ func TestMarshalJobOption(t *testing.T) {
 	})
 }
 
+
+func TestMarshalWorkflowStrategy(t *testing.T) {
+	testMarshalXML(t, []marshalTest{
+		marshalTest{
+			"with-parallel",
+			JobCommandSequence{
+				OrderingStrategy: "parallel",
+				Commands: []JobCommand{
+					JobCommand{
+						ShellCommand: "true",
+					},
+				},
+			},
+			`<sequence keepgoing="false" strategy="parallel"><command><exec>true</exec></command></sequence>`,
+		},
+		marshalTest{
+			"with-ruleset",
+			JobCommandSequence{
+				OrderingStrategy: "ruleset",
+				Commands: []JobCommand{
+					JobCommand{
+						ShellCommand: "true",
+					},
+				},
+				PluginConfig: &JobWorkflowPluginConfig{
+					WorkflowStrategy: JobWorkflowStrategyConfig{
+						"ruleset": JobPluginConfig{
+							"rules": "[*] run-in-sequence",
+						},
+					},
+				},
+			},
+			`<sequence keepgoing="false" strategy="ruleset"><command><exec>true</exec></command><pluginConfig><WorkflowStrategy><ruleset><rules>[*] run-in-sequence</rules></ruleset></WorkflowStrategy></pluginConfig></sequence>`,
+		},
+	})
+}
+
+func TestUnmarshalWorkflowStrategy(t *testing.T) {
+	testUnmarshalXML(t, []unmarshalTest{
+		unmarshalTest{
+			"with-ruleset",
+			`<sequence keepgoing="false" strategy="ruleset"><command><exec>true</exec></command><pluginConfig><WorkflowStrategy><ruleset><rules>[*] run-in-sequence</rules></ruleset><other/></WorkflowStrategy></pluginConfig></sequence>`,
+			&JobCommandSequence{},
+			func(rv interface{}) error {
+				v := rv.(*JobCommandSequence)
+				if v.OrderingStrategy != "ruleset" {
+					return fmt.Errorf("got OrderingStrategy %s, but expecting ruleset", v.OrderingStrategy)
+				}
+				if v.PluginConfig == nil {
+					return fmt.Errorf("got nil PluginConfig, but expecting not nil")
+				}
+				strategies := v.PluginConfig.WorkflowStrategy
+				if len(strategies) != 2 {
+					return fmt.Errorf("got %d WorkflowStrategy entries, but expecting 2", len(strategies))
+				}
+				if got := strategies["ruleset"]["rules"]; got != "[*] run-in-sequence" {
+					return fmt.Errorf("WorkflowStrategy[\"ruleset\"][\"rules\"] = %q, but expecting \"[*] run-in-sequence\"", got)
+				}
+				if len(strategies["other"]) != 0 {
+					return fmt.Errorf("got %d config values for other, but expecting 0", len(strategies["other"]))
+				}
+				return nil
+			},
+		},
+	})
+}
+
+func TestMarshalLogFilters(t *testing.T) {
+	testMarshalXML(t, []marshalTest{
+		marshalTest{
+			"with-log-filters",
+			JobCommandSequence{
+				Commands: []JobCommand{
+					JobCommand{
+						ShellCommand: "true",
+						Plugins: &JobCommandPlugins{
+							LogFilters: []JobLogFilter{
+								JobLogFilter{
+									Type: "key-value-data",
+									Config: JobLogFilterConfig{
+										"regex":   `^RUNDECK:DATA:(.+?)\s*=\s*(.+)$`,
+										"logData": "true",
+									},
+								},
+							},
+						},
+					},
+				},
+				PluginConfig: &JobWorkflowPluginConfig{
+					LogFilters: []JobLogFilter{
+						JobLogFilter{
+							Type: "mask-passwords",
+						},
+					},
+				},
+			},
+			`<sequence keepgoing="false"><command><exec>true</exec><plugins><LogFilter type="key-value-data"><config><logData>true</logData><regex>^RUNDECK:DATA:(.+?)\s*=\s*(.+)$</regex></config></LogFilter></plugins></command><pluginConfig><LogFilter type="mask-passwords"></LogFilter></pluginConfig></sequence>`,
+		},
+	})
+}
+
+func TestUnmarshalLogFilters(t *testing.T) {
+	testUnmarshalXML(t, []unmarshalTest{
+		unmarshalTest{
+			"with-log-filters",
+			`<sequence><command><exec>true</exec><plugins><LogFilter type="highlight-output"><config><bgcolor>yellow</bgcolor><regex>ERROR</regex></config></LogFilter></plugins></command><pluginConfig><LogFilter type="mask-passwords"><config/></LogFilter></pluginConfig></sequence>`,
+			&JobCommandSequence{},
+			func(rv interface{}) error {
+				v := rv.(*JobCommandSequence)
+				if v.PluginConfig == nil || len(v.PluginConfig.LogFilters) != 1 {
+					return fmt.Errorf("got PluginConfig %#v, but expecting one log filter", v.PluginConfig)
+				}
+				if v.PluginConfig.LogFilters[0].Type != "mask-passwords" {
+					return fmt.Errorf("got workflow log filter Type %s, but expecting mask-passwords", v.PluginConfig.LogFilters[0].Type)
+				}
+				plugins := v.Commands[0].Plugins
+				if plugins == nil || len(plugins.LogFilters) != 1 {
+					return fmt.Errorf("got Plugins %#v, but expecting one log filter", plugins)
+				}
+				filter := plugins.LogFilters[0]
+				if filter.Type != "highlight-output" {
+					return fmt.Errorf("got step log filter Type %s, but expecting highlight-output", filter.Type)
+				}
+				if len(filter.Config) != 2 || filter.Config["bgcolor"] != "yellow" || filter.Config["regex"] != "ERROR" {
+					return fmt.Errorf("got step log filter Config %#v, but expecting bgcolor and regex", filter.Config)
+				}
+				return nil
+			},
+		},
+	})
+}
+
+// TestJobRoundTrip checks that exported job documents survive being decoded
+// and re-encoded, including the elements that JobDetail doesn't model. The
+// comparison ignores element order and insignificant whitespace, since
+// Rundeck doesn't care about either.
+func TestJobRoundTrip(t *testing.T) {
+	filenames, err := filepath.Glob("testdata/jobs/*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filenames) == 0 {
+		t.Fatal("no test job documents found")
+	}
+
+	for _, filename := range filenames {
+		input, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		jobList := &jobDetailList{}
+		err = xml.Unmarshal(input, jobList)
+		if err != nil {
+			t.Errorf("Error in Unmarshal for %s: %s", filename, err)
+			continue
+		}
+		output, err := xml.Marshal(jobList)
+		if err != nil {
+			t.Errorf("Error in Marshal for %s: %s", filename, err)
+			continue
+		}
+
+		want, err := canonicalXML(input)
+		if err != nil {
+			t.Fatalf("Error canonicalizing %s: %s", filename, err)
+		}
+		got, err := canonicalXML(output)
+		if err != nil {
+			t.Errorf("Error canonicalizing output for %s: %s", filename, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Round-trip of %s got\n%s\nbut wanted\n%s", filename, got, want)
+		}
+	}
+}
+
+func TestCanonicalXMLSiblingOrder(t *testing.T) {
+	a, err := canonicalXML([]byte(`<n><email/><plugin type="a"/><plugin type="b"/></n>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := canonicalXML([]byte(`<n><plugin type="b"/><plugin type="a"/><email/></n>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("reordering same-named elements didn't change the canonical form:\n%s", a)
+	}
+}
+
+type xmlNode struct {
+	Name     string
+	Attrs    []string
+	Text     string
+	Children []*xmlNode
+}
+
+// canonicalXML renders an XML document in a normalized form where attributes
+// and child elements are sorted by name and surrounding whitespace is trimmed.
+// The sort is stable, so elements with the same name, such as several
+// notification plugins, are still compared in document order.
+func canonicalXML(doc []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+	for {
+		token, err := d.Token()
+		if token == nil {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		current := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: t.Name.Local}
+			for _, attr := range t.Attr {
+				node.Attrs = append(node.Attrs, fmt.Sprintf("%s=%q", attr.Name.Local, attr.Value))
+			}
+			sort.Strings(node.Attrs)
+			current.Children = append(current.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			current.Text = strings.TrimSpace(current.Text)
+			sort.SliceStable(current.Children, func(i, j int) bool {
+				return current.Children[i].Name < current.Children[j].Name
+			})
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			current.Text += string(t)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	var write func(node *xmlNode, indent string)
+	write = func(node *xmlNode, indent string) {
+		fmt.Fprintf(buf, "%s%s %s %q\n", indent, node.Name, strings.Join(node.Attrs, " "), node.Text)
+		for _, child := range node.Children {
+			write(child, indent+"  ")
+		}
+	}
+	for _, child := range root.Children {
+		write(child, "")
+	}
+	return buf.String(), nil
+}
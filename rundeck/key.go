@@ -1,5 +1,12 @@
 package rundeck
 
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
 // KeyMeta is the metadata associated with a resource in the Rundeck key store.
 type KeyMeta struct {
 	XMLName string `xml:"resource"`
@@ -8,13 +15,144 @@ type KeyMeta struct {
 	ResourceType string `xml:"type,attr,omitempty"`
 	URL string `xml:"url,attr,omitempty"`
 	ContentType string `xml:"resource-meta>Rundeck-content-type"`
-	ContentSize string `xml:"resource-meta>Rundeck-content-size"`
+
+	// ContentSize is zero if the server didn't report a valid size.
+	ContentSize int64 `xml:"resource-meta>Rundeck-content-size"`
 	ContentMask string `xml:"resource-meta>Rundeck-content-mask"`
-	KeyType string `xml:"resource-meta>Rundeck-key-type"`
+
+	// KeyType is the kind of data the key holds, or empty for directories.
+	KeyType KeyKind `xml:"resource-meta>Rundeck-key-type"`
+
 	LastModifiedByUserName string `xml:"resource-meta>Rundeck-auth-modified-username"`
 	CreatedByUserName string `xml:"resource-meta>Rundeck-auth-created-username"`
-	CreatedTimestamp string `xml:"resource-meta>Rundeck-content-creation-time"`
-	LastModifiedTimestamp string `xml:"resource-meta>Rundeck-content-modify-time"`
+
+	// CreatedTimestamp and LastModifiedTimestamp are zero if the server
+	// didn't report them or reported them in an unrecognized format.
+	CreatedTimestamp time.Time `xml:"resource-meta>Rundeck-content-creation-time"`
+	LastModifiedTimestamp time.Time `xml:"resource-meta>Rundeck-content-modify-time"`
+}
+
+// keyMetaXML is the representation of KeyMeta in the server's responses,
+// before the values are parsed.
+type keyMetaXML struct {
+	Name                   string `xml:"name,attr"`
+	Path                   string `xml:"path,attr"`
+	ResourceType           string `xml:"type,attr"`
+	URL                    string `xml:"url,attr"`
+	ContentType            string `xml:"resource-meta>Rundeck-content-type"`
+	ContentSize            string `xml:"resource-meta>Rundeck-content-size"`
+	ContentMask            string `xml:"resource-meta>Rundeck-content-mask"`
+	KeyType                string `xml:"resource-meta>Rundeck-key-type"`
+	DataType               string `xml:"resource-meta>Rundeck-data-type"`
+	LastModifiedByUserName string `xml:"resource-meta>Rundeck-auth-modified-username"`
+	CreatedByUserName      string `xml:"resource-meta>Rundeck-auth-created-username"`
+	CreatedTimestamp       string `xml:"resource-meta>Rundeck-content-creation-time"`
+	LastModifiedTimestamp  string `xml:"resource-meta>Rundeck-content-modify-time"`
+}
+
+func (k *KeyMeta) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	raw := keyMetaXML{}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+	raw.keyMeta(k)
+	return nil
+}
+
+// keyMeta parses the raw values into the given KeyMeta. Values that can't be
+// parsed are left as zero rather than failing, since one odd key would
+// otherwise make a whole directory listing fail.
+func (raw *keyMetaXML) keyMeta(k *KeyMeta) {
+	*k = KeyMeta{
+		Name:                   raw.Name,
+		Path:                   raw.Path,
+		ResourceType:           raw.ResourceType,
+		URL:                    raw.URL,
+		ContentType:            raw.ContentType,
+		ContentMask:            raw.ContentMask,
+		KeyType:                KeyKind(raw.KeyType),
+		LastModifiedByUserName: raw.LastModifiedByUserName,
+		CreatedByUserName:      raw.CreatedByUserName,
+	}
+
+	// Passwords are described by a data type rather than a key type, and
+	// older servers give only the content type.
+	if k.KeyType == "" && raw.DataType == "password" {
+		k.KeyType = KeyKindPassword
+	}
+	if k.KeyType == "" {
+		k.KeyType = keyKindForContentType(raw.ContentType)
+	}
+
+	if size, err := strconv.ParseInt(raw.ContentSize, 10, 64); err == nil {
+		k.ContentSize = size
+	}
+	k.CreatedTimestamp = parseKeyTimestamp(raw.CreatedTimestamp)
+	k.LastModifiedTimestamp = parseKeyTimestamp(raw.LastModifiedTimestamp)
+}
+
+// parseKeyTimestamp parses a timestamp from the key store, returning the zero
+// time if it is missing or invalid.
+func parseKeyTimestamp(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// IsDirectory returns true if the resource is a directory rather than a key.
+func (k *KeyMeta) IsDirectory() bool {
+	return k.ResourceType == "directory"
+}
+
+// IsPublic returns true if the resource is a public key.
+func (k *KeyMeta) IsPublic() bool {
+	return k.KeyType == KeyKindPublic
+}
+
+// IsPrivate returns true if the resource is a private key.
+func (k *KeyMeta) IsPrivate() bool {
+	return k.KeyType == KeyKindPrivate
+}
+
+// IsPassword returns true if the resource is a password.
+func (k *KeyMeta) IsPassword() bool {
+	return k.KeyType == KeyKindPassword
+}
+
+// KeyKind is the kind of data held in a key.
+type KeyKind string
+
+// The kinds of key that Rundeck can store.
+const (
+	KeyKindPublic   KeyKind = "public"
+	KeyKindPrivate  KeyKind = "private"
+	KeyKindPassword KeyKind = "password"
+)
+
+// ContentType returns the content type used to store keys of this kind.
+func (k KeyKind) ContentType() string {
+	switch k {
+	case KeyKindPublic:
+		return "application/pgp-keys"
+	case KeyKindPrivate:
+		return "application/octet-stream"
+	case KeyKindPassword:
+		return "application/x-rundeck-data-password"
+	}
+	return ""
+}
+
+// keyKindForContentType returns the kind of key stored with the given
+// content type, or an empty kind if it is not recognized.
+func keyKindForContentType(contentType string) KeyKind {
+	for _, kind := range []KeyKind{KeyKindPublic, KeyKindPrivate, KeyKindPassword} {
+		if kind.ContentType() == contentType {
+			return kind
+		}
+	}
+	return ""
 }
 
 type keyMetaListContents struct {
@@ -23,16 +161,24 @@ type keyMetaListContents struct {
 
 // GetKeyMeta returns the metadata for the key at the given keystore path.
 func (c *Client) GetKeyMeta(path string) (*KeyMeta, error) {
+	parts, err := keyPathParts(path)
+	if err != nil {
+		return nil, err
+	}
 	k := &KeyMeta{}
-	err := c.get([]string{"storage", "keys", path}, nil, k)
+	err = c.get(parts, nil, k)
 	return k, err
 }
 
 // GetKeysInDirMeta returns the metadata for the keys and subdirectories within
 // the directory at the given keystore path.
 func (c *Client) GetKeysInDirMeta(path string) ([]KeyMeta, error) {
+	parts, err := keyPathParts(path)
+	if err != nil {
+		return nil, err
+	}
 	r := &keyMetaListContents{}
-	err := c.get([]string{"storage", "keys", path}, nil, r)
+	err = c.get(parts, nil, r)
 	if err != nil {
 		return nil, err
 	}
@@ -42,48 +188,62 @@ func (c *Client) GetKeysInDirMeta(path string) ([]KeyMeta, error) {
 // GetKeyContent retrieves and returns the content of the key at the given keystore path.
 // Private keys are write-only, so they cannot be retrieved via this interface.
 func (c *Client) GetKeyContent(path string) (string, error) {
-	return c.rawGet([]string{"storage", "keys", path}, nil, "application/pgp-keys")
+	content, err := c.GetKeyContentBytes(path)
+	return string(content), err
 }
 
 func (c *Client) CreatePublicKey(path string, content string) error {
-	return c.createOrReplacePublicKey("POST", path, "application/pgp-keys", content)
+	return c.createOrReplaceKey("POST", path, KeyKindPublic, []byte(content))
 }
 
 func (c *Client) ReplacePublicKey(path string, content string) error {
-	return c.createOrReplacePublicKey("PUT", path, "application/pgp-keys", content)
+	return c.createOrReplaceKey("PUT", path, KeyKindPublic, []byte(content))
 }
 
 func (c *Client) CreatePrivateKey(path string, content string) error {
-	return c.createOrReplacePublicKey("POST", path, "application/octet-stream", content)
+	return c.createOrReplaceKey("POST", path, KeyKindPrivate, []byte(content))
 }
 
 func (c *Client) ReplacePrivateKey(path string, content string) error {
-	return c.createOrReplacePublicKey("PUT", path, "application/octet-stream", content)
+	return c.createOrReplaceKey("PUT", path, KeyKindPrivate, []byte(content))
 }
 
 func (c *Client) CreatePassword(path string, content string) error {
-	return c.createOrReplacePublicKey("POST", path, "application/x-rundeck-data-password", content)
+	return c.createOrReplaceKey("POST", path, KeyKindPassword, []byte(content))
 }
 
 func (c *Client) ReplacePassword(path string, content string) error {
-	return c.createOrReplacePublicKey("PUT", path, "application/x-rundeck-data-password", content)
+	return c.createOrReplaceKey("PUT", path, KeyKindPassword, []byte(content))
 }
 
-func (c *Client) createOrReplacePublicKey(method string, path string, contentType string, content string) error {
+func (c *Client) createOrReplaceKey(method string, path string, kind KeyKind, content []byte) error {
+	contentType := kind.ContentType()
+	if contentType == "" {
+		return fmt.Errorf("invalid key kind %q", kind)
+	}
+	parts, err := keyPathParts(path)
+	if err != nil {
+		return err
+	}
+
 	req := &request{
 		Method: method,
-		PathParts: []string{"storage", "keys", path},
+		PathParts: parts,
 		Headers: map[string]string{
 			"Content-Type": contentType,
 		},
-		BodyBytes: []byte(content),
+		BodyBytes: content,
 	}
 
-	_, err := c.rawRequest(req)
+	_, err = c.rawRequest(req)
 
 	return err
 }
 
 func (c *Client) DeleteKey(path string) error {
-	return c.delete([]string{"storage", "keys", path})
+	parts, err := keyPathParts(path)
+	if err != nil {
+		return err
+	}
+	return c.delete(parts)
 }
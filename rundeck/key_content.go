@@ -0,0 +1,89 @@
+package rundeck
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// KeyNotReadableError is returned when attempting to read the content of a
+// private key or password. Rundeck allows only public keys to be read back;
+// the others can be used only by Rundeck itself.
+type KeyNotReadableError struct {
+	Path string
+	Kind KeyKind
+}
+
+func (err *KeyNotReadableError) Error() string {
+	return fmt.Sprintf("the content of %s key %s cannot be read", err.Kind, err.Path)
+}
+
+// GetKeyContentBytes retrieves and returns the content of the public key at
+// the given keystore path. If the key is a private key or password, the
+// error is a *KeyNotReadableError.
+func (c *Client) GetKeyContentBytes(path string) ([]byte, error) {
+	parts, err := keyPathParts(path)
+	if err != nil {
+		return nil, err
+	}
+	req := &request{
+		Method:    "GET",
+		PathParts: parts,
+		Headers: map[string]string{
+			"Accept": KeyKindPublic.ContentType(),
+		},
+	}
+	content, err := c.rawRequest(req)
+	if err == nil {
+		return content, nil
+	}
+
+	// The server's response when asked for a secret varies between
+	// versions, so we check the key's kind to give a consistent error.
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if meta, metaErr := c.GetKeyMeta(path); metaErr == nil && meta.KeyType != KeyKindPublic {
+		return nil, &KeyNotReadableError{
+			Path: path,
+			Kind: meta.KeyType,
+		}
+	}
+	return nil, err
+}
+
+// CopyKeyContent writes the content of the public key at the given keystore
+// path to w, returning the number of bytes written. It fails in the same way
+// as GetKeyContentBytes.
+func (c *Client) CopyKeyContent(path string, w io.Writer) (int64, error) {
+	content, err := c.GetKeyContentBytes(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(content)
+	return int64(n), err
+}
+
+// CreateKey creates a key of the given kind at the given keystore path, with
+// the content read from r. The content is sent unmodified, so private keys
+// may be in binary formats.
+//
+// Keys are small, so the content is read fully before the request is made.
+func (c *Client) CreateKey(path string, kind KeyKind, content io.Reader) error {
+	return c.createOrReplaceKeyFrom("POST", path, kind, content)
+}
+
+// ReplaceKey replaces the content of the existing key at the given keystore
+// path with the content read from r, in the same way as CreateKey.
+func (c *Client) ReplaceKey(path string, kind KeyKind, content io.Reader) error {
+	return c.createOrReplaceKeyFrom("PUT", path, kind, content)
+}
+
+func (c *Client) createOrReplaceKeyFrom(method string, path string, kind KeyKind, content io.Reader) error {
+	body, err := ioutil.ReadAll(content)
+	if err != nil {
+		return fmt.Errorf("error reading content for key %s: %s", path, err)
+	}
+	return c.createOrReplaceKey(method, path, kind, body)
+}
@@ -0,0 +1,75 @@
+package rundeck_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+func TestKeyContentBinary(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	// A DER-encoded key contains bytes that aren't valid UTF-8.
+	der := []byte{0x30, 0x82, 0x04, 0xa4, 0x02, 0x01, 0x00, 0xff, 0xfe, 0x00, 0x80}
+	if err := client.CreateKey("ops/id_der", rundeck.KeyKindPrivate, bytes.NewReader(der)); err != nil {
+		t.Fatalf("error creating key: %s", err)
+	}
+	if content, _ := server.KeyContent("ops/id_der"); !bytes.Equal(content, der) {
+		t.Errorf("server has content %x, but wanted %x", content, der)
+	}
+	meta, err := client.GetKeyMeta("ops/id_der")
+	if err != nil {
+		t.Fatalf("error getting key meta: %s", err)
+	}
+	if !meta.IsPrivate() || meta.ContentSize != int64(len(der)) {
+		t.Errorf("got meta %#v, but wanted an %d-byte private key", meta, len(der))
+	}
+
+	pub := []byte("ssh-ed25519 AAAAC3Nza\x00\xff")
+	pubBuf := bytes.NewBuffer(pub)
+	if err := client.ReplaceKey("ops/ssh/id_rsa.pub", rundeck.KeyKindPublic, pubBuf); err != nil {
+		t.Fatalf("error replacing key: %s", err)
+	}
+	if pubBuf.Len() != 0 {
+		t.Errorf("%d bytes left unread in the content buffer", pubBuf.Len())
+	}
+	buf := &bytes.Buffer{}
+	n, err := client.CopyKeyContent("ops/ssh/id_rsa.pub", buf)
+	if err != nil {
+		t.Fatalf("error reading key: %s", err)
+	}
+	if n != int64(len(pub)) || !bytes.Equal(buf.Bytes(), pub) {
+		t.Errorf("read %d bytes %q, but wanted %q", n, buf.Bytes(), pub)
+	}
+
+	if err := client.CreateKey("ops/bad", rundeck.KeyKind("certificate"), bytes.NewReader(nil)); err == nil {
+		t.Errorf("creating key of invalid kind succeeded; want error")
+	}
+}
+
+func TestKeyContentNotReadable(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	for path, kind := range map[string]rundeck.KeyKind{
+		"ops/ssh/id_rsa":  rundeck.KeyKindPrivate,
+		"ops/db/password": rundeck.KeyKindPassword,
+	} {
+		_, err := client.GetKeyContentBytes(path)
+		var notReadable *rundeck.KeyNotReadableError
+		if !errors.As(err, &notReadable) {
+			t.Errorf("reading %s: got %#v, but wanted *KeyNotReadableError", path, err)
+			continue
+		}
+		if notReadable.Path != path || notReadable.Kind != kind {
+			t.Errorf("reading %s: got %#v", path, notReadable)
+		}
+	}
+
+	if _, err := client.GetKeyContentBytes("ops/missing"); !errors.Is(err, rundeck.ErrNotFound) {
+		t.Errorf("reading missing key: got %#v, but wanted ErrNotFound", err)
+	}
+}
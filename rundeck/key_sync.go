@@ -0,0 +1,266 @@
+package rundeck
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// KeySpec describes the desired state of a key, for use with SyncKeys.
+type KeySpec struct {
+	Kind    KeyKind
+	Content string
+
+	// ModifiedAt is the time the content was last changed at its source,
+	// such as the update time of a secret in Vault. Since the content of
+	// private keys and passwords can't be read back from Rundeck, an
+	// existing secret of the same kind and size is considered unchanged only
+	// if it was modified in Rundeck after this time. If ModifiedAt is zero,
+	// existing secrets are always replaced.
+	ModifiedAt time.Time
+}
+
+// KeySyncAction is the action that a key sync plan takes for a key.
+type KeySyncAction string
+
+// The actions that a key sync plan can take.
+const (
+	KeySyncCreate    KeySyncAction = "create"
+	KeySyncReplace   KeySyncAction = "replace"
+	KeySyncDelete    KeySyncAction = "delete"
+	KeySyncUnchanged KeySyncAction = "unchanged"
+)
+
+// KeySyncChange is a single entry in a KeySyncPlan.
+type KeySyncChange struct {
+	// Path is the keystore path of the key.
+	Path string
+
+	Action KeySyncAction
+
+	// Kind is the desired kind of the key, or its current kind if it is to
+	// be deleted.
+	Kind KeyKind
+
+	// Reason explains why the action was chosen.
+	Reason string
+
+	// Applied is set once the change has been made.
+	Applied bool
+
+	spec *KeySpec
+}
+
+// KeySyncPlan is the set of changes needed to bring part of the key store to
+// a desired state. It never includes the content of any key, so it is safe
+// to log.
+type KeySyncPlan struct {
+	Root    string
+	Changes []KeySyncChange
+}
+
+// HasChanges returns true if the plan includes any action other than
+// KeySyncUnchanged.
+func (p *KeySyncPlan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Action != KeySyncUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// KeySyncOptions controls the behavior of SyncKeys.
+type KeySyncOptions struct {
+	// DryRun causes SyncKeys to return the plan without applying it.
+	DryRun bool
+
+	// KeepUnmanaged prevents SyncKeys from deleting existing keys beneath
+	// the root that are not in the desired set.
+	KeepUnmanaged bool
+}
+
+// PlanKeySync compares the keys beneath the given keystore path with the
+// desired set, whose keys are paths relative to root, and returns the
+// changes needed to make them match.
+func (c *Client) PlanKeySync(root string, desired map[string]KeySpec, opts KeySyncOptions) (*KeySyncPlan, error) {
+	rootPath, err := ParseKeyPath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing root just means that there are no existing keys, but any
+	// error from within the walk must fail the plan, since a partial view of
+	// the existing keys would give wrong creates and miss deletes.
+	existing := map[string]*KeyMeta{}
+	if _, err := c.GetKeyMeta(rootPath.String()); err == nil {
+		err = c.WalkKeys(rootPath.String(), func(key *KeyMeta, err error) error {
+			if err != nil {
+				return err
+			}
+			if !key.IsDirectory() {
+				existing[key.KeyPath().String()] = key
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	plan := &KeySyncPlan{
+		Root: rootPath.String(),
+	}
+	seen := map[string]bool{}
+	for relPath, spec := range desired {
+		spec := spec
+		if spec.Kind.ContentType() == "" {
+			return nil, fmt.Errorf("invalid kind %q for key %s", spec.Kind, relPath)
+		}
+		path, err := ParseKeyPath(rootPath.Join(relPath).String())
+		if err != nil {
+			return nil, err
+		}
+		if path == rootPath {
+			return nil, &KeyPathError{relPath, "does not name a key beneath the root"}
+		}
+		seen[path.String()] = true
+		change := KeySyncChange{
+			Path: path.String(),
+			Kind: spec.Kind,
+			spec: &spec,
+		}
+		change.Action, change.Reason, err = c.keySyncAction(existing[path.String()], &spec)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	if !opts.KeepUnmanaged {
+		for path, key := range existing {
+			if seen[path] {
+				continue
+			}
+			plan.Changes = append(plan.Changes, KeySyncChange{
+				Path:   path,
+				Action: KeySyncDelete,
+				Kind:   key.KeyType,
+				Reason: "not in desired set",
+			})
+		}
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Path < plan.Changes[j].Path
+	})
+	return plan, nil
+}
+
+func (c *Client) keySyncAction(existing *KeyMeta, spec *KeySpec) (KeySyncAction, string, error) {
+	if existing == nil {
+		return KeySyncCreate, "does not exist", nil
+	}
+	if existing.KeyType != spec.Kind {
+		return KeySyncReplace, fmt.Sprintf("kind changes from %s to %s", existing.KeyType, spec.Kind), nil
+	}
+
+	if spec.Kind == KeyKindPublic {
+		content, err := c.GetKeyContent(existing.KeyPath().String())
+		if err != nil {
+			return "", "", err
+		}
+		if content != spec.Content {
+			return KeySyncReplace, "content differs", nil
+		}
+		return KeySyncUnchanged, "content matches", nil
+	}
+
+	if existing.ContentSize != int64(len(spec.Content)) {
+		return KeySyncReplace, "size differs", nil
+	}
+	if spec.ModifiedAt.IsZero() {
+		return KeySyncReplace, "secret content can't be compared", nil
+	}
+	if existing.LastModifiedTimestamp.IsZero() {
+		return KeySyncReplace, "modification time unknown", nil
+	}
+	if !existing.LastModifiedTimestamp.After(spec.ModifiedAt) {
+		return KeySyncReplace, "modified at source since last sync", nil
+	}
+	return KeySyncUnchanged, "modified in Rundeck since source changed", nil
+}
+
+// ApplyKeySync makes the changes in a plan returned by PlanKeySync, stopping
+// at the first error. Keys are created and replaced before any are deleted.
+// A key whose kind changes is deleted and then recreated, since Rundeck
+// doesn't allow the kind of an existing key to be changed.
+//
+// Since plans never expose key content, creates and replaces in a plan that
+// wasn't returned by PlanKeySync, such as one decoded from JSON, fail with an
+// error.
+func (c *Client) ApplyKeySync(plan *KeySyncPlan) error {
+	for _, pass := range []KeySyncAction{KeySyncCreate, KeySyncReplace, KeySyncDelete} {
+		for i := range plan.Changes {
+			change := &plan.Changes[i]
+			if change.Action != pass || change.Applied {
+				continue
+			}
+			if err := c.applyKeySyncChange(change); err != nil {
+				return fmt.Errorf("failed to %s key %s: %s", change.Action, change.Path, err)
+			}
+			change.Applied = true
+		}
+	}
+	return nil
+}
+
+func (c *Client) applyKeySyncChange(change *KeySyncChange) error {
+	switch change.Action {
+	case KeySyncDelete:
+		return c.DeleteKey(change.Path)
+	case KeySyncCreate, KeySyncReplace:
+	default:
+		return nil
+	}
+
+	// The content is held only by plans returned from PlanKeySync.
+	spec := change.spec
+	if spec == nil {
+		return fmt.Errorf("plan has no content for this key; it must be created by PlanKeySync")
+	}
+	if change.Action == KeySyncCreate {
+		return c.CreateKey(change.Path, spec.Kind, strings.NewReader(spec.Content))
+	}
+
+	meta, err := c.GetKeyMeta(change.Path)
+	if err != nil {
+		return err
+	}
+	if meta.KeyType != spec.Kind {
+		if err := c.DeleteKey(change.Path); err != nil {
+			return err
+		}
+		return c.CreateKey(change.Path, spec.Kind, strings.NewReader(spec.Content))
+	}
+	return c.ReplaceKey(change.Path, spec.Kind, strings.NewReader(spec.Content))
+}
+
+// SyncKeys converges the keys beneath the given keystore path to the
+// desired set, whose keys are paths relative to root. Existing keys beneath
+// root that are not in the desired set are deleted unless
+// opts.KeepUnmanaged is set.
+//
+// The returned plan describes the changes made, or that would have been
+// made if opts.DryRun is set. If applying the plan fails, the plan is
+// returned along with the error, and its Applied fields show how far it got.
+func (c *Client) SyncKeys(root string, desired map[string]KeySpec, opts KeySyncOptions) (*KeySyncPlan, error) {
+	plan, err := c.PlanKeySync(root, desired, opts)
+	if err != nil || opts.DryRun {
+		return plan, err
+	}
+	return plan, c.ApplyKeySync(plan)
+}
@@ -0,0 +1,162 @@
+package rundeck_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+func TestSyncKeys(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	longAgo := time.Now().Add(-24 * time.Hour)
+	desired := map[string]rundeck.KeySpec{
+		// Same size and modified in Rundeck since the source changed.
+		"db/password": {Kind: rundeck.KeyKindPassword, Content: "hunter3", ModifiedAt: longAgo},
+		// Kind changes from private to password.
+		"ssh/id_rsa":     {Kind: rundeck.KeyKindPassword, Content: "now a password"},
+		"ssh/id_rsa.pub": {Kind: rundeck.KeyKindPublic, Content: "ssh-rsa AAAA"},
+		"api/token":      {Kind: rundeck.KeyKindPassword, Content: "abc123"},
+	}
+	desiredAfter := map[string]rundeck.KeySpec{
+		"db/password":    desired["db/password"],
+		"ssh/id_rsa":     {Kind: rundeck.KeyKindPassword, Content: "now a password", ModifiedAt: longAgo},
+		"ssh/id_rsa.pub": desired["ssh/id_rsa.pub"],
+		"api/token":      {Kind: rundeck.KeyKindPassword, Content: "abc123", ModifiedAt: longAgo},
+	}
+	server.AddKey("ops/stale", "application/x-rundeck-data-password", []byte("old"))
+
+	plan, err := client.SyncKeys("ops", desired, rundeck.KeySyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("error planning sync: %s", err)
+	}
+	got := map[string]rundeck.KeySyncAction{}
+	for _, change := range plan.Changes {
+		got[change.Path] = change.Action
+		if change.Applied {
+			t.Errorf("dry run applied change to %s", change.Path)
+		}
+	}
+	want := map[string]rundeck.KeySyncAction{
+		"ops/api/token":      rundeck.KeySyncCreate,
+		"ops/db/password":    rundeck.KeySyncUnchanged,
+		"ops/ssh/id_rsa":     rundeck.KeySyncReplace,
+		"ops/ssh/id_rsa.pub": rundeck.KeySyncUnchanged,
+		"ops/stale":          rundeck.KeySyncDelete,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got plan %#v, but wanted %#v", got, want)
+	}
+	if _, err := client.GetKeyMeta("ops/api/token"); err == nil {
+		t.Errorf("dry run created ops/api/token")
+	}
+
+	plan, err = client.SyncKeys("ops", desired, rundeck.KeySyncOptions{})
+	if err != nil {
+		t.Fatalf("error applying sync: %s", err)
+	}
+	for _, change := range plan.Changes {
+		if change.Action != rundeck.KeySyncUnchanged && !change.Applied {
+			t.Errorf("change to %s was not applied", change.Path)
+		}
+	}
+	if content, ok := server.KeyContent("ops/api/token"); !ok || string(content) != "abc123" {
+		t.Errorf("got ops/api/token content %q, but wanted abc123", content)
+	}
+	if content, ok := server.KeyContent("ops/ssh/id_rsa"); !ok || string(content) != "now a password" {
+		t.Errorf("got ops/ssh/id_rsa content %q, but wanted the new password", content)
+	}
+	if _, ok := server.KeyContent("ops/stale"); ok {
+		t.Errorf("ops/stale was not deleted")
+	}
+	if _, ok := server.KeyContent("dev/token"); !ok {
+		t.Errorf("dev/token outside the root was deleted")
+	}
+
+	plan, err = client.SyncKeys("ops", desiredAfter, rundeck.KeySyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("error planning second sync: %s", err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("second sync has changes: %#v", plan.Changes)
+	}
+}
+
+func TestSyncKeysEmptyRoot(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	plan, err := client.SyncKeys("new", map[string]rundeck.KeySpec{
+		"token": {Kind: rundeck.KeyKindPassword, Content: "abc"},
+	}, rundeck.KeySyncOptions{})
+	if err != nil {
+		t.Fatalf("error syncing to new root: %s", err)
+	}
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != rundeck.KeySyncCreate {
+		t.Errorf("got plan %#v, but wanted a single create", plan.Changes)
+	}
+}
+
+func TestPlanKeySyncVanishingDirectory(t *testing.T) {
+	server, _ := newKeyWalkServer(t)
+	defer server.Close()
+
+	// ops/ssh disappears between listing ops and listing ops/ssh.
+	config := server.ClientConfig()
+	config.Middleware = []func(http.RoundTripper) http.RoundTripper{
+		func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if strings.HasSuffix(req.URL.Path, "/storage/keys/ops/ssh") {
+					return &http.Response{
+						StatusCode: http.StatusNotFound,
+						Header:     http.Header{},
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+						Request:    req,
+					}, nil
+				}
+				return next.RoundTrip(req)
+			})
+		},
+	}
+	client, err := rundeck.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.PlanKeySync("ops", map[string]rundeck.KeySpec{
+		"ssh/id_rsa.pub": {Kind: rundeck.KeyKindPublic, Content: "ssh-rsa AAAA"},
+	}, rundeck.KeySyncOptions{})
+	if err == nil {
+		t.Errorf("plan succeeded with a partial walk; want error")
+	}
+}
+
+func TestApplyKeySyncWithoutContent(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	plan := &rundeck.KeySyncPlan{
+		Root: "ops",
+		Changes: []rundeck.KeySyncChange{
+			{Path: "ops/new", Action: rundeck.KeySyncCreate, Kind: rundeck.KeyKindPassword},
+		},
+	}
+	if err := client.ApplyKeySync(plan); err == nil {
+		t.Errorf("applied a create with no content; want error")
+	}
+	if plan.Changes[0].Applied {
+		t.Errorf("change without content was marked as applied")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
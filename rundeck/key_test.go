@@ -0,0 +1,121 @@
+package rundeck
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalKeyMeta(t *testing.T) {
+	testUnmarshalXML(t, []unmarshalTest{
+		unmarshalTest{
+			"private-key",
+			`<resource path="keys/ops/id_rsa" type="file" name="id_rsa"><resource-meta><Rundeck-content-type>application/octet-stream</Rundeck-content-type><Rundeck-content-size>1679</Rundeck-content-size><Rundeck-content-mask>content</Rundeck-content-mask><Rundeck-key-type>private</Rundeck-key-type><Rundeck-content-creation-time>2015-06-01T10:00:00Z</Rundeck-content-creation-time><Rundeck-content-modify-time>2016-01-02T03:04:05Z</Rundeck-content-modify-time></resource-meta></resource>`,
+			&KeyMeta{},
+			func(rv interface{}) error {
+				v := rv.(*KeyMeta)
+				if v.ContentSize != 1679 {
+					return fmt.Errorf("got ContentSize %d, but expecting 1679", v.ContentSize)
+				}
+				if !v.IsPrivate() || v.IsPassword() || v.IsPublic() || v.IsDirectory() {
+					return fmt.Errorf("got KeyType %q, but expecting private", v.KeyType)
+				}
+				if want := time.Date(2015, 6, 1, 10, 0, 0, 0, time.UTC); !v.CreatedTimestamp.Equal(want) {
+					return fmt.Errorf("got CreatedTimestamp %s, but expecting %s", v.CreatedTimestamp, want)
+				}
+				if want := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC); !v.LastModifiedTimestamp.Equal(want) {
+					return fmt.Errorf("got LastModifiedTimestamp %s, but expecting %s", v.LastModifiedTimestamp, want)
+				}
+				return nil
+			},
+		},
+		unmarshalTest{
+			"password",
+			`<resource path="keys/db" type="file"><resource-meta><Rundeck-content-type>application/x-rundeck-data-password</Rundeck-content-type><Rundeck-content-size>7</Rundeck-content-size><Rundeck-data-type>password</Rundeck-data-type></resource-meta></resource>`,
+			&KeyMeta{},
+			func(rv interface{}) error {
+				v := rv.(*KeyMeta)
+				if !v.IsPassword() {
+					return fmt.Errorf("got KeyType %q, but expecting password", v.KeyType)
+				}
+				if !v.CreatedTimestamp.IsZero() {
+					return fmt.Errorf("got CreatedTimestamp %s, but expecting zero", v.CreatedTimestamp)
+				}
+				return nil
+			},
+		},
+		unmarshalTest{
+			"public-key-content-type-only",
+			`<resource path="keys/id_rsa.pub" type="file"><resource-meta><Rundeck-content-type>application/pgp-keys</Rundeck-content-type></resource-meta></resource>`,
+			&KeyMeta{},
+			func(rv interface{}) error {
+				v := rv.(*KeyMeta)
+				if !v.IsPublic() {
+					return fmt.Errorf("got KeyType %q, but expecting public", v.KeyType)
+				}
+				return nil
+			},
+		},
+		unmarshalTest{
+			"directory",
+			`<resource path="keys/ops" type="directory"><contents count="1"><resource path="keys/ops/db" type="file"><resource-meta><Rundeck-content-size>3</Rundeck-content-size><Rundeck-data-type>password</Rundeck-data-type></resource-meta></resource></contents></resource>`,
+			&keyMetaListContents{},
+			func(rv interface{}) error {
+				v := rv.(*keyMetaListContents)
+				if len(v.Keys) != 1 {
+					return fmt.Errorf("got %d keys, but expecting 1", len(v.Keys))
+				}
+				if !v.Keys[0].IsPassword() || v.Keys[0].ContentSize != 3 {
+					return fmt.Errorf("got key %#v, but expecting a 3-byte password", v.Keys[0])
+				}
+				return nil
+			},
+		},
+	})
+}
+
+func TestUnmarshalKeyMetaInvalidValues(t *testing.T) {
+	k := &KeyMeta{}
+	err := xml.Unmarshal([]byte(`<resource path="keys/db"><resource-meta><Rundeck-content-size>big</Rundeck-content-size><Rundeck-content-modify-time>yesterday</Rundeck-content-modify-time></resource-meta></resource>`), k)
+	if err != nil {
+		t.Fatalf("error unmarshalling invalid values: %s", err)
+	}
+	if k.ContentSize != 0 || !k.LastModifiedTimestamp.IsZero() {
+		t.Errorf("got size %d and modification time %s, but wanted zero values", k.ContentSize, k.LastModifiedTimestamp)
+	}
+	if k.Path != "keys/db" {
+		t.Errorf("got path %q, but wanted keys/db", k.Path)
+	}
+}
+
+func TestGetKeysInDirMetaInvalidValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<resource path="keys/ops" type="directory"><contents count="2">` +
+			`<resource path="keys/ops/odd" type="file"><resource-meta><Rundeck-content-size>unknown</Rundeck-content-size><Rundeck-content-creation-time>Tue Jan 05 10:00:00 UTC 2016</Rundeck-content-creation-time><Rundeck-data-type>password</Rundeck-data-type></resource-meta></resource>` +
+			`<resource path="keys/ops/db" type="file"><resource-meta><Rundeck-content-size>7</Rundeck-content-size><Rundeck-data-type>password</Rundeck-data-type></resource-meta></resource>` +
+			`</contents></resource>`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := client.GetKeysInDirMeta("ops")
+	if err != nil {
+		t.Fatalf("error listing directory: %s", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("got %d keys, but wanted 2", len(keys))
+	}
+	if keys[0].ContentSize != 0 || !keys[0].CreatedTimestamp.IsZero() || !keys[0].IsPassword() {
+		t.Errorf("got odd key %#v, but wanted a password with zero size and time", keys[0])
+	}
+	if keys[1].ContentSize != 7 {
+		t.Errorf("got size %d for keys/ops/db, but wanted 7", keys[1].ContentSize)
+	}
+}
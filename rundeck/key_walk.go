@@ -0,0 +1,253 @@
+package rundeck
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SkipKeyDir can be returned by a KeyWalkFunc when called for a directory, to
+// skip the contents of that directory. It is not returned as an error by any
+// function.
+var SkipKeyDir = errors.New("skip this key directory")
+
+// KeyWalkFunc is the type of the function called by WalkKeys for each key
+// and directory visited.
+//
+// If a directory's contents could not be listed, the function is called a
+// second time for that directory with a non-nil err. Returning nil (or
+// SkipKeyDir) from that call allows the walk to continue.
+//
+// If the function returns an error other than SkipKeyDir, the walk stops and
+// WalkKeys returns that error.
+type KeyWalkFunc func(key *KeyMeta, err error) error
+
+// keyDirectory is the representation of a key storage directory, which
+// includes the metadata of its immediate contents.
+type keyDirectory struct {
+	keyMetaXML
+	Contents []KeyMeta `xml:"contents>resource"`
+}
+
+// WalkKeys visits the resource at the given keystore path and, if it is a
+// directory, everything beneath it, calling fn for each. Directories are
+// visited before their contents, and the contents of each directory are
+// visited in lexical order.
+func (c *Client) WalkKeys(root string, fn KeyWalkFunc) error {
+	return c.WalkKeysParallel(root, 1, fn)
+}
+
+// WalkKeysParallel is like WalkKeys but lists up to the given number of
+// directories at once, which is much faster for large key stores.
+//
+// Calls to fn are never concurrent, but the order in which the contents of
+// different directories are visited is unspecified. Each directory is still
+// visited before its contents.
+func (c *Client) WalkKeysParallel(root string, parallelism int, fn KeyWalkFunc) error {
+	parts, err := keyPathParts(root)
+	if err != nil {
+		return err
+	}
+	dir := &keyDirectory{}
+	if err := c.get(parts, nil, dir); err != nil {
+		return err
+	}
+
+	w := &keyWalker{
+		client: c,
+		fn:     fn,
+	}
+	if parallelism > 1 {
+		w.sem = make(chan struct{}, parallelism)
+	}
+
+	meta := KeyMeta{}
+	dir.keyMeta(&meta)
+	if err := w.visit(&meta, nil); err != nil || !meta.IsDirectory() {
+		return w.result(err)
+	}
+	w.walkContents(dir.Contents)
+	w.wg.Wait()
+	return w.result(nil)
+}
+
+// keyWalker holds the state of a single walk.
+type keyWalker struct {
+	client *Client
+	fn     KeyWalkFunc
+
+	// sem limits the number of concurrent directory listings, or is nil if
+	// the walk is sequential.
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// visit calls the walk function, unless the walk has already stopped.
+func (w *keyWalker) visit(key *KeyMeta, err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.fn(key, err); err != nil {
+		if err != SkipKeyDir {
+			w.err = err
+		}
+		return err
+	}
+	return nil
+}
+
+func (w *keyWalker) stopped() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err != nil
+}
+
+func (w *keyWalker) result(err error) error {
+	if err == SkipKeyDir {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *keyWalker) walkContents(contents []KeyMeta) {
+	sort.Slice(contents, func(i, j int) bool {
+		return contents[i].Path < contents[j].Path
+	})
+	for i := range contents {
+		key := &contents[i]
+		if err := w.visit(key, nil); err != nil {
+			if err == SkipKeyDir {
+				continue
+			}
+			return
+		}
+		if !key.IsDirectory() {
+			continue
+		}
+		if w.sem == nil {
+			w.walkDir(key)
+			continue
+		}
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.walkDir(key)
+		}()
+	}
+}
+
+func (w *keyWalker) walkDir(key *KeyMeta) {
+	if w.stopped() {
+		return
+	}
+	if w.sem != nil {
+		w.sem <- struct{}{}
+	}
+	dir := &keyDirectory{}
+	err := w.client.get(key.KeyPath().pathParts(), nil, dir)
+	if w.sem != nil {
+		<-w.sem
+	}
+	if err != nil {
+		w.visit(key, err)
+		return
+	}
+	w.walkContents(dir.Contents)
+}
+
+// KeyManifest describes the contents of part of the key store, for auditing.
+// It never contains the content of private keys or passwords.
+type KeyManifest struct {
+	// Root is the keystore path that the manifest describes.
+	Root string `json:"root"`
+
+	// GeneratedAt is the time the manifest was created.
+	GeneratedAt time.Time `json:"generatedAt"`
+
+	// Keys describes each key beneath the root, in order of path.
+	Keys []KeyManifestEntry `json:"keys"`
+}
+
+// KeyManifestEntry describes a single key within a KeyManifest.
+type KeyManifestEntry struct {
+	Path                   string    `json:"path"`
+	ContentType            string    `json:"contentType"`
+	ContentSize            int64     `json:"contentSize"`
+	KeyType                KeyKind   `json:"keyType,omitempty"`
+	CreatedByUserName      string    `json:"createdBy,omitempty"`
+	LastModifiedByUserName string    `json:"lastModifiedBy,omitempty"`
+	CreatedTimestamp       time.Time `json:"created"`
+	LastModifiedTimestamp  time.Time `json:"lastModified"`
+
+	// Content is the content of the key, included only for public keys.
+	Content string `json:"content,omitempty"`
+}
+
+// keyWalkParallelism is the number of directories GetKeyManifest lists at
+// once.
+const keyWalkParallelism = 4
+
+// GetKeyManifest walks the key store beneath the given path and returns a
+// manifest of the keys found there, including the content of public keys.
+func (c *Client) GetKeyManifest(root string) (*KeyManifest, error) {
+	manifest := &KeyManifest{
+		Root:        root,
+		GeneratedAt: time.Now().UTC(),
+		Keys:        []KeyManifestEntry{},
+	}
+	err := c.WalkKeysParallel(root, keyWalkParallelism, func(key *KeyMeta, err error) error {
+		if err != nil {
+			return err
+		}
+		if key.IsDirectory() {
+			return nil
+		}
+		manifest.Keys = append(manifest.Keys, KeyManifestEntry{
+			Path:                   key.KeyPath().String(),
+			ContentType:            key.ContentType,
+			ContentSize:            key.ContentSize,
+			KeyType:                key.KeyType,
+			CreatedByUserName:      key.CreatedByUserName,
+			LastModifiedByUserName: key.LastModifiedByUserName,
+			CreatedTimestamp:       key.CreatedTimestamp,
+			LastModifiedTimestamp:  key.LastModifiedTimestamp,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(manifest.Keys, func(i, j int) bool {
+		return manifest.Keys[i].Path < manifest.Keys[j].Path
+	})
+	for i := range manifest.Keys {
+		entry := &manifest.Keys[i]
+		if entry.KeyType != KeyKindPublic {
+			continue
+		}
+		content, err := c.GetKeyContent(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		entry.Content = content
+	}
+	return manifest, nil
+}
+
+// WriteJSON writes the manifest to the given writer as indented JSON.
+func (m *KeyManifest) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
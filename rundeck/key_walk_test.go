@@ -0,0 +1,151 @@
+package rundeck_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+	"github.com/apparentlymart/go-rundeck-api/rundeck/rundecktest"
+)
+
+func newKeyWalkServer(t *testing.T) (*rundecktest.Server, *rundeck.Client) {
+	server := rundecktest.NewServer()
+	server.AddKey("ops/db/password", "application/x-rundeck-data-password", []byte("hunter2"))
+	server.AddKey("ops/ssh/id_rsa", "application/octet-stream", []byte("PRIVATE"))
+	server.AddKey("ops/ssh/id_rsa.pub", "application/pgp-keys", []byte("ssh-rsa AAAA"))
+	server.AddKey("dev/token", "application/x-rundeck-data-password", []byte("secret"))
+	client, err := server.NewClient()
+	if err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+	return server, client
+}
+
+func TestWalkKeys(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	var visited []string
+	err := client.WalkKeys("", func(key *rundeck.KeyMeta, err error) error {
+		if err != nil {
+			return err
+		}
+		if key.Path == "keys/dev" {
+			return rundeck.SkipKeyDir
+		}
+		visited = append(visited, key.ResourceType+" "+key.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking keys: %s", err)
+	}
+
+	want := []string{
+		"directory keys",
+		"directory keys/ops",
+		"directory keys/ops/db",
+		"file keys/ops/db/password",
+		"directory keys/ops/ssh",
+		"file keys/ops/ssh/id_rsa",
+		"file keys/ops/ssh/id_rsa.pub",
+	}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited %#v, but wanted %#v", visited, want)
+	}
+}
+
+func TestWalkKeysParallel(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	var mu sync.Mutex
+	var visited []string
+	err := client.WalkKeysParallel("ops", 3, func(key *rundeck.KeyMeta, err error) error {
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		visited = append(visited, key.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error walking keys: %s", err)
+	}
+	sort.Strings(visited)
+
+	want := []string{
+		"keys/ops",
+		"keys/ops/db",
+		"keys/ops/db/password",
+		"keys/ops/ssh",
+		"keys/ops/ssh/id_rsa",
+		"keys/ops/ssh/id_rsa.pub",
+	}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("visited %#v, but wanted %#v", visited, want)
+	}
+
+	stop := errors.New("stop")
+	err = client.WalkKeysParallel("", 3, func(key *rundeck.KeyMeta, err error) error {
+		if !key.IsDirectory() {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("got %v from stopped walk, but wanted %v", err, stop)
+	}
+}
+
+func TestGetKeyManifest(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	manifest, err := client.GetKeyManifest("ops")
+	if err != nil {
+		t.Fatalf("error getting manifest: %s", err)
+	}
+
+	var paths []string
+	for _, entry := range manifest.Keys {
+		paths = append(paths, entry.Path)
+		switch entry.Path {
+		case "ops/ssh/id_rsa.pub":
+			if entry.Content != "ssh-rsa AAAA" {
+				t.Errorf("got public key content %q, but wanted ssh-rsa AAAA", entry.Content)
+			}
+		default:
+			if entry.Content != "" {
+				t.Errorf("manifest includes content of secret %s", entry.Path)
+			}
+		}
+	}
+	want := []string{"ops/db/password", "ops/ssh/id_rsa", "ops/ssh/id_rsa.pub"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %#v, but wanted %#v", paths, want)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := manifest.WriteJSON(buf); err != nil {
+		t.Fatalf("error writing manifest: %s", err)
+	}
+	for _, secret := range []string{"hunter2", "PRIVATE"} {
+		if bytes.Contains(buf.Bytes(), []byte(secret)) {
+			t.Errorf("manifest JSON contains secret %q", secret)
+		}
+	}
+	var decoded rundeck.KeyManifest
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("error decoding manifest: %s", err)
+	}
+	if len(decoded.Keys) != 3 || decoded.Root != "ops" {
+		t.Errorf("decoded manifest %#v does not match", decoded)
+	}
+}
@@ -0,0 +1,138 @@
+package rundeck
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// KeyPath is a normalized path within the Rundeck key store, relative to
+// its root, such as "ops/ssh/id_rsa". The empty KeyPath is the root.
+//
+// Use ParseKeyPath to create a KeyPath from user input. The Client methods
+// that take key store paths as strings parse them in the same way.
+type KeyPath string
+
+// KeyPathError is returned when a key store path is invalid.
+type KeyPathError struct {
+	Path   string
+	Reason string
+}
+
+func (err *KeyPathError) Error() string {
+	return fmt.Sprintf("invalid key path %q: %s", err.Path, err.Reason)
+}
+
+// ParseKeyPath normalizes and validates a key store path.
+//
+// Leading, trailing and repeated slashes are removed, as is a leading "keys"
+// segment, since the server reports paths with that prefix. Segments may
+// not be "." or "..", or contain control characters.
+func ParseKeyPath(s string) (KeyPath, error) {
+	segments := splitKeyPath(s)
+	if len(segments) > 0 && segments[0] == "keys" {
+		segments = segments[1:]
+	}
+	for _, seg := range segments {
+		if seg == "." || seg == ".." {
+			return "", &KeyPathError{s, fmt.Sprintf("segment %q is not allowed", seg)}
+		}
+		if i := strings.IndexFunc(seg, unicode.IsControl); i >= 0 {
+			return "", &KeyPathError{s, "contains a control character"}
+		}
+	}
+	return KeyPath(strings.Join(segments, "/")), nil
+}
+
+// splitKeyPath splits a path into its non-empty segments.
+func splitKeyPath(s string) []string {
+	segments := []string{}
+	for _, seg := range strings.Split(s, "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+// String returns the path in its normalized form.
+func (p KeyPath) String() string {
+	return string(p)
+}
+
+// IsRoot returns true if the path is the root of the key store.
+func (p KeyPath) IsRoot() bool {
+	return p == ""
+}
+
+// Segments returns the names of the directories leading to the resource,
+// followed by the name of the resource itself.
+func (p KeyPath) Segments() []string {
+	return splitKeyPath(string(p))
+}
+
+// Join returns the path with the given elements appended, each of which may
+// contain slashes. The result is not validated until it is used.
+func (p KeyPath) Join(elem ...string) KeyPath {
+	segments := p.Segments()
+	for _, e := range elem {
+		segments = append(segments, splitKeyPath(e)...)
+	}
+	return KeyPath(strings.Join(segments, "/"))
+}
+
+// Parent returns the path of the directory containing the resource. The
+// parent of the root is the root.
+func (p KeyPath) Parent() KeyPath {
+	segments := p.Segments()
+	if len(segments) == 0 {
+		return p
+	}
+	return KeyPath(strings.Join(segments[:len(segments)-1], "/"))
+}
+
+// Base returns the name of the resource, or the empty string for the root.
+func (p KeyPath) Base() string {
+	segments := p.Segments()
+	if len(segments) == 0 {
+		return ""
+	}
+	return segments[len(segments)-1]
+}
+
+// Escaped returns the path with each segment escaped for use in a URL path.
+func (p KeyPath) Escaped() string {
+	segments := p.Segments()
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// pathParts returns the API path parts for the resource.
+func (p KeyPath) pathParts() []string {
+	return append([]string{"storage", "keys"}, p.Segments()...)
+}
+
+// keyPathParts parses a key store path given to a Client method and returns
+// the API path parts for it.
+func keyPathParts(s string) ([]string, error) {
+	p, err := ParseKeyPath(s)
+	if err != nil {
+		return nil, err
+	}
+	return p.pathParts(), nil
+}
+
+// KeyPath returns the path of the resource relative to the root of the key
+// store, as expected by the Client methods.
+func (k *KeyMeta) KeyPath() KeyPath {
+	p, err := ParseKeyPath(k.Path)
+	if err != nil {
+		// The server gave us a path we wouldn't accept ourselves, so we use
+		// it verbatim and let the server judge it if it's used again.
+		return KeyPath(strings.TrimPrefix(strings.TrimPrefix(k.Path, "keys"), "/"))
+	}
+	return p
+}
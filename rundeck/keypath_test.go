@@ -0,0 +1,94 @@
+package rundeck
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeyPath(t *testing.T) {
+	tests := []struct {
+		Input string
+		Want  KeyPath
+	}{
+		{"", ""},
+		{"/", ""},
+		{"keys", ""},
+		{"ops/db", "ops/db"},
+		{"/ops//db/", "ops/db"},
+		{"keys/ops/db", "ops/db"},
+		{"/keys/ops/db", "ops/db"},
+		{"ops/keys/db", "ops/keys/db"},
+		{"team a/id #1?", "team a/id #1?"},
+	}
+	for _, test := range tests {
+		got, err := ParseKeyPath(test.Input)
+		if err != nil {
+			t.Errorf("ParseKeyPath(%q) returned error: %s", test.Input, err)
+			continue
+		}
+		if got != test.Want {
+			t.Errorf("ParseKeyPath(%q) is %q, but wanted %q", test.Input, got, test.Want)
+		}
+	}
+
+	for _, input := range []string{"ops/../db", "./ops", "ops/db\n", "ops/\x00"} {
+		if _, err := ParseKeyPath(input); err == nil {
+			t.Errorf("ParseKeyPath(%q) succeeded; want error", input)
+		} else if _, ok := err.(*KeyPathError); !ok {
+			t.Errorf("ParseKeyPath(%q) returned %#v, but wanted *KeyPathError", input, err)
+		}
+	}
+}
+
+func TestKeyPathOperations(t *testing.T) {
+	p := KeyPath("ops/ssh")
+
+	if got := p.Join("web", "/id_rsa"); got != "ops/ssh/web/id_rsa" {
+		t.Errorf("Join is %q, but wanted ops/ssh/web/id_rsa", got)
+	}
+	if got := KeyPath("").Join("a/b"); got != "a/b" {
+		t.Errorf("Join from root is %q, but wanted a/b", got)
+	}
+	if got := p.Parent(); got != "ops" {
+		t.Errorf("Parent is %q, but wanted ops", got)
+	}
+	if got := p.Parent().Parent(); !got.IsRoot() {
+		t.Errorf("Parent of top-level path is %q, but wanted the root", got)
+	}
+	if got := KeyPath("").Parent(); !got.IsRoot() {
+		t.Errorf("Parent of root is %q, but wanted the root", got)
+	}
+	if got := p.Base(); got != "ssh" {
+		t.Errorf("Base is %q, but wanted ssh", got)
+	}
+	if got := KeyPath("").Base(); got != "" {
+		t.Errorf("Base of root is %q, but wanted empty", got)
+	}
+	if got := KeyPath("team a/id #1?").Escaped(); got != "team%20a/id%20%231%3F" {
+		t.Errorf("Escaped is %q", got)
+	}
+	if got, want := p.pathParts(), []string{"storage", "keys", "ops", "ssh"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pathParts is %#v, but wanted %#v", got, want)
+	}
+	if got := (&KeyMeta{Path: "keys/ops/ssh"}).KeyPath(); got != p {
+		t.Errorf("KeyMeta.KeyPath is %q, but wanted %q", got, p)
+	}
+}
+
+func TestKeyMethodsRejectInvalidPaths(t *testing.T) {
+	// The client is never used to make a request, since the path is rejected
+	// first.
+	client, err := NewClient(&ClientConfig{BaseURL: "http://rundeck.invalid/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetKeyMeta("../etc/passwd"); err == nil {
+		t.Errorf("GetKeyMeta succeeded; want error")
+	}
+	if err := client.CreatePassword("ops/../db", "secret"); err == nil {
+		t.Errorf("CreatePassword succeeded; want error")
+	}
+	if err := client.DeleteKey("./db"); err == nil {
+		t.Errorf("DeleteKey succeeded; want error")
+	}
+}
@@ -2,6 +2,8 @@ package rundeck
 
 import (
 	"encoding/xml"
+	"fmt"
+	"sort"
 )
 
 // ProjectSummary provides the basic identifying information for a project within Rundeck.
@@ -76,6 +78,145 @@ func (c *Client) SetProjectConfig(projectName string, config ProjectConfig) erro
 	)
 }
 
+// GetProjectConfig returns the configuration of the named project.
+func (c *Client) GetProjectConfig(projectName string) (ProjectConfig, error) {
+	config := ProjectConfig{}
+	err := c.get([]string{"project", projectName, "config"}, nil, &config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// GetProjectConfigKey returns the value of a single configuration key of the
+// named project. If the key is not set, the error matches ErrNotFound.
+func (c *Client) GetProjectConfigKey(projectName string, key string) (string, error) {
+	return c.rawGet([]string{"project", projectName, "config", key}, nil, "text/plain")
+}
+
+// SetProjectConfigKey sets a single configuration key of the named project,
+// leaving the others unchanged.
+func (c *Client) SetProjectConfigKey(projectName string, key string, value string) error {
+	req := &request{
+		Method:    "PUT",
+		PathParts: []string{"project", projectName, "config", key},
+		Headers: map[string]string{
+			"Accept":       "text/plain",
+			"Content-Type": "text/plain",
+		},
+		BodyBytes: []byte(value),
+	}
+	_, err := c.rawRequest(req)
+	return err
+}
+
+// DeleteProjectConfigKey removes a single configuration key from the named
+// project, leaving the others unchanged.
+func (c *Client) DeleteProjectConfigKey(projectName string, key string) error {
+	return c.delete([]string{"project", projectName, "config", key})
+}
+
+// MergeProjectConfig sets the given configuration keys and removes the keys
+// named in remove, leaving all other keys of the named project unchanged.
+// Unlike SetProjectConfig, this is safe when several tools each manage
+// different keys of the same project.
+//
+// Keys that already have the desired state are not written. The changes are
+// made one key at a time, so if an error is returned some of them may
+// already have been made.
+func (c *Client) MergeProjectConfig(projectName string, set ProjectConfig, remove []string) error {
+	for _, k := range remove {
+		if _, ok := set[k]; ok {
+			return fmt.Errorf("key %s is both set and removed", k)
+		}
+	}
+
+	current, err := c.GetProjectConfig(projectName)
+	if err != nil {
+		return err
+	}
+	return c.updateProjectConfig(projectName, current, set, remove)
+}
+
+// updateProjectConfig makes the writes needed to apply set and remove to a
+// project whose configuration is currently current.
+func (c *Client) updateProjectConfig(projectName string, current ProjectConfig, set ProjectConfig, remove []string) error {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v, ok := current[k]; ok && v == set[k] {
+			continue
+		}
+		if err := c.SetProjectConfigKey(projectName, k, set[k]); err != nil {
+			return fmt.Errorf("error setting %s: %s", k, err)
+		}
+	}
+
+	for _, k := range remove {
+		if _, ok := current[k]; !ok {
+			continue
+		}
+		if err := c.DeleteProjectConfigKey(projectName, k); err != nil {
+			return fmt.Errorf("error removing %s: %s", k, err)
+		}
+	}
+	return nil
+}
+
+// GetProjectReadme returns the content of the named project's readme, which
+// is shown on the project's landing page as Markdown.
+func (c *Client) GetProjectReadme(projectName string) (string, error) {
+	return c.getProjectFile(projectName, "readme.md")
+}
+
+// SetProjectReadme replaces the content of the named project's readme.
+func (c *Client) SetProjectReadme(projectName string, content string) error {
+	return c.putProjectFile(projectName, "readme.md", content)
+}
+
+// DeleteProjectReadme removes the named project's readme.
+func (c *Client) DeleteProjectReadme(projectName string) error {
+	return c.delete([]string{"project", projectName, "readme.md"})
+}
+
+// GetProjectMOTD returns the content of the named project's message of the
+// day, which is shown prominently to users of the project as Markdown.
+func (c *Client) GetProjectMOTD(projectName string) (string, error) {
+	return c.getProjectFile(projectName, "motd.md")
+}
+
+// SetProjectMOTD replaces the content of the named project's message of the
+// day.
+func (c *Client) SetProjectMOTD(projectName string, content string) error {
+	return c.putProjectFile(projectName, "motd.md", content)
+}
+
+// DeleteProjectMOTD removes the named project's message of the day.
+func (c *Client) DeleteProjectMOTD(projectName string) error {
+	return c.delete([]string{"project", projectName, "motd.md"})
+}
+
+func (c *Client) getProjectFile(projectName string, file string) (string, error) {
+	return c.rawGet([]string{"project", projectName, file}, nil, "text/plain")
+}
+
+func (c *Client) putProjectFile(projectName string, file string, content string) error {
+	req := &request{
+		Method:    "PUT",
+		PathParts: []string{"project", projectName, file},
+		Headers: map[string]string{
+			"Accept":       "text/plain",
+			"Content-Type": "text/plain",
+		},
+		BodyBytes: []byte(content),
+	}
+	_, err := c.rawRequest(req)
+	return err
+}
+
 func (c ProjectConfig) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	rc := map[string]string(c)
 	return marshalMapToXML(&rc, e, start, "property", "key", "value")
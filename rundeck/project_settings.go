@@ -0,0 +1,279 @@
+package rundeck
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProjectSettings is a typed view of the parts of a ProjectConfig that
+// configure how Rundeck finds and connects to nodes, and how long it keeps
+// execution history.
+//
+// Use ParseProjectSettings to decode a ProjectSettings from a ProjectConfig,
+// and Config or ApplyTo to encode it back again. Fields with zero values are
+// omitted from the configuration, so that Rundeck uses its defaults.
+type ProjectSettings struct {
+	// ResourceSources are the resource model sources that provide the
+	// project's nodes, in the order that Rundeck consults them.
+	ResourceSources []ResourceSource
+
+	// NodeExecutor and FileCopier are the names of the default providers
+	// used to run commands on nodes and to copy files to them, such as
+	// "jsch-ssh" and "jsch-scp".
+	NodeExecutor string
+	FileCopier   string
+
+	SSH SSHSettings
+
+	ExecutionCleanup ExecutionCleanupSettings
+}
+
+// SSHAuthentication is the method used to authenticate SSH connections to
+// nodes.
+type SSHAuthentication string
+
+// The SSH authentication methods supported by Rundeck.
+const (
+	SSHAuthPrivateKey SSHAuthentication = "privateKey"
+	SSHAuthPassword   SSHAuthentication = "password"
+)
+
+// SSHSettings are the project-wide defaults for the built-in SSH node
+// executor and file copier.
+type SSHSettings struct {
+	Authentication SSHAuthentication
+
+	// KeyPath is the path of a private key file on the Rundeck server.
+	KeyPath string
+
+	// KeyStoragePath and PasswordStoragePath are paths in the key store of
+	// the private key or password to use, such as "keys/ops/ssh/id_rsa".
+	KeyStoragePath      string
+	PasswordStoragePath string
+
+	ConnectTimeout time.Duration
+	CommandTimeout time.Duration
+}
+
+// ExecutionCleanupSettings configure the periodic removal of old executions
+// from a project's history.
+type ExecutionCleanupSettings struct {
+	Enabled bool
+
+	// RetentionDays is the age in days after which executions are removed.
+	RetentionDays int
+
+	// MinimumRetained is the number of most recent executions that are kept
+	// regardless of their age.
+	MinimumRetained int
+
+	// BatchSize is the maximum number of executions removed in each run.
+	BatchSize int
+
+	// Schedule is the Quartz cron expression giving when cleanup runs.
+	Schedule string
+}
+
+// The configuration keys used by ProjectSettings.
+const (
+	projectConfigResourceSourcePrefix = "resources.source."
+	projectConfigNodeExecutor         = "service.NodeExecutor.default.provider"
+	projectConfigFileCopier           = "service.FileCopier.default.provider"
+	projectConfigSSHAuthentication    = "project.ssh-authentication"
+	projectConfigSSHKeyPath           = "project.ssh-keypath"
+	projectConfigSSHKeyStoragePath    = "project.ssh-key-storage-path"
+	projectConfigSSHPasswordPath      = "project.ssh-password-storage-path"
+	projectConfigSSHConnectTimeout    = "project.ssh-connect-timeout"
+	projectConfigSSHCommandTimeout    = "project.ssh-command-timeout"
+	projectConfigCleanupPrefix        = "project.execution.history.cleanup."
+	projectConfigCleanupEnabled       = projectConfigCleanupPrefix + "enabled"
+	projectConfigCleanupRetentionDays = projectConfigCleanupPrefix + "retention.days"
+	projectConfigCleanupMinimum       = projectConfigCleanupPrefix + "retention.minimum"
+	projectConfigCleanupBatch         = projectConfigCleanupPrefix + "batch"
+	projectConfigCleanupSchedule      = projectConfigCleanupPrefix + "schedule"
+)
+
+// isProjectSettingsKey returns true if the given configuration key is
+// managed by ProjectSettings.
+func isProjectSettingsKey(key string) bool {
+	if strings.HasPrefix(key, projectConfigResourceSourcePrefix) {
+		return true
+	}
+	switch key {
+	case projectConfigNodeExecutor, projectConfigFileCopier,
+		projectConfigSSHAuthentication, projectConfigSSHKeyPath,
+		projectConfigSSHKeyStoragePath, projectConfigSSHPasswordPath,
+		projectConfigSSHConnectTimeout, projectConfigSSHCommandTimeout,
+		projectConfigCleanupEnabled, projectConfigCleanupRetentionDays,
+		projectConfigCleanupMinimum, projectConfigCleanupBatch,
+		projectConfigCleanupSchedule:
+		return true
+	}
+	return false
+}
+
+// ParseProjectSettings decodes the settings from a project configuration.
+// Keys that aren't managed by ProjectSettings are ignored.
+func ParseProjectSettings(config ProjectConfig) (*ProjectSettings, error) {
+	s := &ProjectSettings{
+		NodeExecutor: config[projectConfigNodeExecutor],
+		FileCopier:   config[projectConfigFileCopier],
+		SSH: SSHSettings{
+			Authentication:      SSHAuthentication(config[projectConfigSSHAuthentication]),
+			KeyPath:             config[projectConfigSSHKeyPath],
+			KeyStoragePath:      config[projectConfigSSHKeyStoragePath],
+			PasswordStoragePath: config[projectConfigSSHPasswordPath],
+		},
+		ExecutionCleanup: ExecutionCleanupSettings{
+			Schedule: config[projectConfigCleanupSchedule],
+		},
+	}
+
+	var err error
+	if s.ResourceSources, err = parseResourceSources(config); err != nil {
+		return nil, err
+	}
+
+	if s.SSH.ConnectTimeout, err = parseConfigMillis(config, projectConfigSSHConnectTimeout); err != nil {
+		return nil, err
+	}
+	if s.SSH.CommandTimeout, err = parseConfigMillis(config, projectConfigSSHCommandTimeout); err != nil {
+		return nil, err
+	}
+
+	if v, ok := config[projectConfigCleanupEnabled]; ok {
+		if s.ExecutionCleanup.Enabled, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid value %q for %s: must be true or false", v, projectConfigCleanupEnabled)
+		}
+	}
+	if s.ExecutionCleanup.RetentionDays, err = parseConfigInt(config, projectConfigCleanupRetentionDays); err != nil {
+		return nil, err
+	}
+	if s.ExecutionCleanup.MinimumRetained, err = parseConfigInt(config, projectConfigCleanupMinimum); err != nil {
+		return nil, err
+	}
+	if s.ExecutionCleanup.BatchSize, err = parseConfigInt(config, projectConfigCleanupBatch); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func parseConfigInt(config ProjectConfig, key string) (int, error) {
+	v, ok := config[key]
+	if !ok || v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value %q for %s: must be a non-negative integer", v, key)
+	}
+	return n, nil
+}
+
+func parseConfigMillis(config ProjectConfig, key string) (time.Duration, error) {
+	n, err := parseConfigInt(config, key)
+	return time.Duration(n) * time.Millisecond, err
+}
+
+// Config returns the configuration keys that represent the settings.
+func (s *ProjectSettings) Config() ProjectConfig {
+	config := ProjectConfig{}
+	setResourceSourcesConfig(config, s.ResourceSources)
+
+	setConfigString(config, projectConfigNodeExecutor, s.NodeExecutor)
+	setConfigString(config, projectConfigFileCopier, s.FileCopier)
+
+	setConfigString(config, projectConfigSSHAuthentication, string(s.SSH.Authentication))
+	setConfigString(config, projectConfigSSHKeyPath, s.SSH.KeyPath)
+	setConfigString(config, projectConfigSSHKeyStoragePath, s.SSH.KeyStoragePath)
+	setConfigString(config, projectConfigSSHPasswordPath, s.SSH.PasswordStoragePath)
+	setConfigInt(config, projectConfigSSHConnectTimeout, int(s.SSH.ConnectTimeout/time.Millisecond))
+	setConfigInt(config, projectConfigSSHCommandTimeout, int(s.SSH.CommandTimeout/time.Millisecond))
+
+	cleanup := s.ExecutionCleanup
+	if cleanup != (ExecutionCleanupSettings{}) {
+		config[projectConfigCleanupEnabled] = strconv.FormatBool(cleanup.Enabled)
+	}
+	setConfigInt(config, projectConfigCleanupRetentionDays, cleanup.RetentionDays)
+	setConfigInt(config, projectConfigCleanupMinimum, cleanup.MinimumRetained)
+	setConfigInt(config, projectConfigCleanupBatch, cleanup.BatchSize)
+	setConfigString(config, projectConfigCleanupSchedule, cleanup.Schedule)
+
+	return config
+}
+
+// ApplyTo replaces the keys of config that are managed by ProjectSettings
+// with those representing s, leaving all other keys unchanged.
+func (s *ProjectSettings) ApplyTo(config ProjectConfig) {
+	for k := range config {
+		if isProjectSettingsKey(k) {
+			delete(config, k)
+		}
+	}
+	for k, v := range s.Config() {
+		config[k] = v
+	}
+}
+
+func setConfigString(config ProjectConfig, key string, value string) {
+	if value != "" {
+		config[key] = value
+	}
+}
+
+func setConfigInt(config ProjectConfig, key string, value int) {
+	if value != 0 {
+		config[key] = strconv.Itoa(value)
+	}
+}
+
+// GetProjectSettings returns the settings of the named project.
+func (c *Client) GetProjectSettings(projectName string) (*ProjectSettings, error) {
+	config, err := c.GetProjectConfig(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProjectSettings(config)
+}
+
+// UpdateProjectSettings changes the configuration of the named project to
+// match settings. Only the keys managed by ProjectSettings are changed, and
+// only those whose values differ are written, as with MergeProjectConfig.
+// Resource sources are written in the same way as by SetResourceSources.
+func (c *Client) UpdateProjectSettings(projectName string, settings *ProjectSettings) error {
+	for i := range settings.ResourceSources {
+		if err := settings.ResourceSources[i].validate(); err != nil {
+			return err
+		}
+	}
+
+	current, err := c.GetProjectConfig(projectName)
+	if err != nil {
+		return err
+	}
+
+	// The resource sources are written separately, so that Rundeck never
+	// sees a partly-updated source.
+	set := settings.Config()
+	for k := range set {
+		if strings.HasPrefix(k, projectConfigResourceSourcePrefix) {
+			delete(set, k)
+		}
+	}
+	var remove []string
+	for k := range current {
+		if _, ok := set[k]; !ok && isProjectSettingsKey(k) && !strings.HasPrefix(k, projectConfigResourceSourcePrefix) {
+			remove = append(remove, k)
+		}
+	}
+	sort.Strings(remove)
+
+	if err := c.updateProjectConfig(projectName, current, set, remove); err != nil {
+		return err
+	}
+	return c.writeResourceSources(projectName, current, settings.ResourceSources)
+}
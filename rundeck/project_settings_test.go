@@ -0,0 +1,163 @@
+package rundeck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseProjectSettings(t *testing.T) {
+	config := ProjectConfig{
+		"project.name":                                     "example",
+		"resources.source.2.type":                          "url",
+		"resources.source.2.config.url":                    "https://cmdb.example.com/nodes.xml",
+		"resources.source.2.config.timeout":                "30",
+		"resources.source.1.type":                          "file",
+		"resources.source.1.config.file":                   "/var/rundeck/projects/example/etc/resources.xml",
+		"resources.source.1.config.format":                 "resourcexml",
+		"service.NodeExecutor.default.provider":            "jsch-ssh",
+		"service.FileCopier.default.provider":              "jsch-scp",
+		"project.ssh-authentication":                       "privateKey",
+		"project.ssh-key-storage-path":                     "keys/ops/ssh/id_rsa",
+		"project.ssh-connect-timeout":                      "15000",
+		"project.execution.history.cleanup.enabled":        "true",
+		"project.execution.history.cleanup.retention.days": "60",
+		"project.execution.history.cleanup.batch":          "500",
+		"project.execution.history.cleanup.schedule":       "0 0 0 1/1 * ? *",
+	}
+
+	got, err := ParseProjectSettings(config)
+	if err != nil {
+		t.Fatalf("error parsing settings: %s", err)
+	}
+	want := &ProjectSettings{
+		ResourceSources: []ResourceSource{
+			{
+				Type: "file",
+				Config: map[string]string{
+					"file":   "/var/rundeck/projects/example/etc/resources.xml",
+					"format": "resourcexml",
+				},
+			},
+			{
+				Type: "url",
+				Config: map[string]string{
+					"url":     "https://cmdb.example.com/nodes.xml",
+					"timeout": "30",
+				},
+			},
+		},
+		NodeExecutor: "jsch-ssh",
+		FileCopier:   "jsch-scp",
+		SSH: SSHSettings{
+			Authentication: SSHAuthPrivateKey,
+			KeyStoragePath: "keys/ops/ssh/id_rsa",
+			ConnectTimeout: 15 * time.Second,
+		},
+		ExecutionCleanup: ExecutionCleanupSettings{
+			Enabled:       true,
+			RetentionDays: 60,
+			BatchSize:     500,
+			Schedule:      "0 0 0 1/1 * ? *",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, but wanted %#v", got, want)
+	}
+
+	// Encoding the settings again gives back the same keys, except that
+	// the project name isn't managed by ProjectSettings.
+	wantConfig := ProjectConfig{}
+	for k, v := range config {
+		wantConfig[k] = v
+	}
+	delete(wantConfig, "project.name")
+	if gotConfig := got.Config(); !reflect.DeepEqual(gotConfig, wantConfig) {
+		t.Errorf("got config %#v, but wanted %#v", gotConfig, wantConfig)
+	}
+}
+
+func TestParseProjectSettingsErrors(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Config ProjectConfig
+		Error  string
+	}{
+		{
+			"bad-index",
+			ProjectConfig{"resources.source.x.type": "file"},
+			"not a positive number",
+		},
+		{
+			"zero-index",
+			ProjectConfig{"resources.source.0.type": "file"},
+			"not a positive number",
+		},
+		{
+			"no-type",
+			ProjectConfig{"resources.source.1.config.file": "/tmp/nodes.xml"},
+			"resource source 1 has no type",
+		},
+		{
+			"unknown-property",
+			ProjectConfig{
+				"resources.source.1.type":  "file",
+				"resources.source.1.other": "x",
+			},
+			"unrecognized resource source key",
+		},
+		{
+			"bad-timeout",
+			ProjectConfig{"project.ssh-command-timeout": "soon"},
+			"project.ssh-command-timeout",
+		},
+		{
+			"bad-enabled",
+			ProjectConfig{"project.execution.history.cleanup.enabled": "maybe"},
+			"must be true or false",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseProjectSettings(test.Config)
+		if err == nil {
+			t.Errorf("%s: got no error, but wanted one", test.Name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.Error) {
+			t.Errorf("%s: got error %q, but wanted it to contain %q", test.Name, err, test.Error)
+		}
+	}
+}
+
+func TestProjectSettingsApplyTo(t *testing.T) {
+	config := ProjectConfig{
+		"project.name":                   "example",
+		"resources.source.1.type":        "file",
+		"resources.source.1.config.file": "/tmp/old.xml",
+		"resources.source.3.type":        "directory",
+		"project.ssh-keypath":            "/home/rundeck/.ssh/id_rsa",
+	}
+	settings := &ProjectSettings{
+		ResourceSources: []ResourceSource{
+			{Type: "url", Config: map[string]string{"url": "https://cmdb.example.com/nodes.xml"}},
+		},
+		SSH: SSHSettings{
+			Authentication:      SSHAuthPassword,
+			PasswordStoragePath: "keys/ops/db/password",
+		},
+	}
+	settings.ApplyTo(config)
+
+	want := ProjectConfig{
+		"project.name":                      "example",
+		"resources.source.1.type":           "url",
+		"resources.source.1.config.url":     "https://cmdb.example.com/nodes.xml",
+		"project.ssh-authentication":        "password",
+		"project.ssh-password-storage-path": "keys/ops/db/password",
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %#v, but wanted %#v", config, want)
+	}
+}
@@ -0,0 +1,179 @@
+package rundeck
+
+import (
+	"sync"
+	"time"
+)
+
+// EndpointClass groups API endpoints that should share a rate limit.
+type EndpointClass string
+
+// The classes of endpoint that can be given their own limits using
+// ClientConfig.EndpointLimits.
+const (
+	// EndpointRead is any request that only reads data.
+	EndpointRead EndpointClass = "read"
+
+	// EndpointWrite is any request that changes data, other than those in
+	// the more specific classes below.
+	EndpointWrite EndpointClass = "write"
+
+	// EndpointImport is a bulk job import, which is expensive for the server
+	// to process.
+	EndpointImport EndpointClass = "import"
+
+	// EndpointRun is a request that starts a job or command executing.
+	EndpointRun EndpointClass = "run"
+)
+
+// Limit describes a limit on the rate and concurrency of API requests.
+// A zero value for any field means that aspect is not limited.
+type Limit struct {
+	// RequestsPerSecond is the sustained rate at which requests may be
+	// started, enforced with a token bucket.
+	RequestsPerSecond float64
+
+	// Burst is the number of requests that may be started at once before the
+	// rate limit applies. Values less than one are treated as one.
+	Burst int
+
+	// MaxInFlight is the maximum number of requests that may be outstanding
+	// at the same time.
+	MaxInFlight int
+}
+
+// limiter enforces a Limit. It is safe for concurrent use.
+type limiter struct {
+	rate  float64
+	burst float64
+	sem   chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit Limit) *limiter {
+	l := &limiter{
+		rate:  limit.RequestsPerSecond,
+		burst: float64(limit.Burst),
+	}
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	l.tokens = l.burst
+	if limit.MaxInFlight > 0 {
+		l.sem = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+// acquire blocks until a request may be started. Each call must be followed
+// by a call to release once the request is complete.
+func (l *limiter) acquire() {
+	// Take a concurrency slot first, so that we don't use up rate tokens
+	// while waiting for a slot.
+	if l.sem != nil {
+		l.sem <- struct{}{}
+	}
+	if l.rate <= 0 {
+		return
+	}
+	for {
+		wait := l.take()
+		if wait == 0 {
+			return
+		}
+		time.Sleep(wait)
+	}
+}
+
+// take consumes a token if one is available, or otherwise returns how long to
+// wait before one will be.
+func (l *limiter) take() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+func (l *limiter) release() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// limiters holds the limiters for a client.
+type limiters struct {
+	all     *limiter
+	classes map[EndpointClass]*limiter
+}
+
+func newLimiters(config *ClientConfig) *limiters {
+	if config.RateLimit == nil && len(config.EndpointLimits) == 0 {
+		return nil
+	}
+	ls := &limiters{
+		classes: map[EndpointClass]*limiter{},
+	}
+	if config.RateLimit != nil {
+		ls.all = newLimiter(*config.RateLimit)
+	}
+	for class, limit := range config.EndpointLimits {
+		ls.classes[class] = newLimiter(limit)
+	}
+	return ls
+}
+
+// acquire blocks until a request of the given class may be started, and
+// returns a function to call once it is complete.
+func (ls *limiters) acquire(class EndpointClass) func() {
+	if ls == nil {
+		return func() {}
+	}
+	// The class limiter is acquired first so that requests held back by a
+	// strict class limit don't occupy slots in the overall limit.
+	acquired := make([]*limiter, 0, 2)
+	for _, l := range []*limiter{ls.classes[class], ls.all} {
+		if l != nil {
+			l.acquire()
+			acquired = append(acquired, l)
+		}
+	}
+	return func() {
+		for _, l := range acquired {
+			l.release()
+		}
+	}
+}
+
+// endpointClass returns the class of the given request, for rate limiting.
+func (r *request) endpointClass() EndpointClass {
+	if r.Class != "" {
+		return r.Class
+	}
+	if r.Method == "GET" || r.Method == "HEAD" {
+		return EndpointRead
+	}
+	parts := r.PathParts
+	if len(parts) == 3 && parts[0] == "job" && (parts[2] == "run" || parts[2] == "executions") {
+		return EndpointRun
+	}
+	if len(parts) == 4 && parts[0] == "project" && parts[2] == "run" {
+		return EndpointRun
+	}
+	return EndpointWrite
+}
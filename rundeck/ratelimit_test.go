@@ -0,0 +1,118 @@
+package rundeck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLimiterRate(t *testing.T) {
+	l := newLimiter(Limit{
+		RequestsPerSecond: 50,
+		Burst:             2,
+	})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		l.acquire()
+		l.release()
+	}
+	// The first two requests use the burst, and the remaining four must each
+	// wait 20ms for a token.
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("six requests took %s, but wanted about 80ms", elapsed)
+	}
+}
+
+func TestClientLimits(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := map[string]int{}
+	maxInFlight := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kind := "read"
+		if r.Method != "GET" {
+			kind = "import"
+		}
+		mu.Lock()
+		inFlight[kind]++
+		if inFlight[kind] > maxInFlight[kind] {
+			maxInFlight[kind] = inFlight[kind]
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[kind]--
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/xml")
+		if r.Method == "GET" {
+			w.Write([]byte(`<jobs count="0"></jobs>`))
+		} else {
+			w.Write([]byte(`<result><succeeded count="1"><job index="1"><id>abc</id><name>test</name><group></group><project>example</project></job></succeeded></result>`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&ClientConfig{
+		BaseURL: server.URL,
+		RateLimit: &Limit{
+			MaxInFlight: 3,
+		},
+		EndpointLimits: map[EndpointClass]Limit{
+			EndpointImport: {
+				MaxInFlight: 1,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetJobSummariesForProject("example"); err != nil {
+				t.Errorf("error listing jobs: %s", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			job := &JobDetail{Name: "test", ProjectName: "example"}
+			if _, err := client.CreateOrUpdateJob(job); err != nil {
+				t.Errorf("error importing job: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight["import"] != 1 {
+		t.Errorf("got %d imports in flight at once, but wanted 1", maxInFlight["import"])
+	}
+	if maxInFlight["read"] > 3 {
+		t.Errorf("got up to %d reads in flight at once, but wanted no more than 3", maxInFlight["read"])
+	}
+}
+
+func TestEndpointClass(t *testing.T) {
+	tests := []struct {
+		Request *request
+		Want    EndpointClass
+	}{
+		{&request{Method: "GET", PathParts: []string{"project", "example", "jobs"}}, EndpointRead},
+		{&request{Method: "DELETE", PathParts: []string{"job", "abc"}}, EndpointWrite},
+		{&request{Method: "POST", PathParts: []string{"job", "abc", "run"}}, EndpointRun},
+		{&request{Method: "POST", PathParts: []string{"job", "abc", "executions"}}, EndpointRun},
+		{&request{Method: "POST", PathParts: []string{"project", "example", "run", "command"}}, EndpointRun},
+		{&request{Method: "POST", PathParts: []string{"jobs", "import"}, Class: EndpointImport}, EndpointImport},
+	}
+	for _, test := range tests {
+		if got := test.Request.endpointClass(); got != test.Want {
+			t.Errorf("%s %v: got class %q, but wanted %q", test.Request.Method, test.Request.PathParts, got, test.Want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package rundeck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseNodesJSON reads a resourcejson document, which is an object mapping
+// node names to objects of attributes. An array of attribute objects is also
+// accepted, as Rundeck does.
+func parseNodesJSON(r io.Reader) ([]Node, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	var close json.Delim
+	switch tok {
+	case json.Delim('{'):
+		close = '}'
+	case json.Delim('['):
+		close = ']'
+	default:
+		return nil, fmt.Errorf("document must be an object or array, not %v", tok)
+	}
+
+	var nodes []Node
+	for dec.More() {
+		// Node objects keep the order of the document, so an object must be
+		// read one key at a time.
+		key := ""
+		if close == '}' {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key = tok.(string)
+		}
+
+		var attrs map[string]interface{}
+		if err := dec.Decode(&attrs); err != nil {
+			return nil, err
+		}
+		if attrs == nil {
+			return nil, fmt.Errorf("node %d is not an object", len(nodes)+1)
+		}
+
+		node := Node{Name: key}
+		for name, value := range attrs {
+			s, err := jsonNodeAttribute(value)
+			if err != nil {
+				return nil, fmt.Errorf("node %d attribute %s: %s", len(nodes)+1, name, err)
+			}
+			node.setAttribute(name, s)
+		}
+		if node.Name == "" {
+			return nil, fmt.Errorf("node %d has no name", len(nodes)+1)
+		}
+		nodes = append(nodes, node)
+	}
+
+	if tok, err := dec.Token(); err != nil || tok != close {
+		return nil, fmt.Errorf("unterminated document")
+	}
+	return nodes, nil
+}
+
+// jsonNodeAttribute converts an attribute value from a resourcejson document
+// to a string. Arrays, as used for tags, are joined with commas.
+func jsonNodeAttribute(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, nested := item.([]interface{}); nested {
+				return "", fmt.Errorf("nested arrays are not supported")
+			}
+			s, err := jsonNodeAttribute(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("objects are not supported")
+	}
+}
+
+func writeNodesJSON(w io.Writer, nodes []Node) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := range nodes {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(&buf, nodes[i].Name)
+		buf.WriteString(":{")
+		for j, attr := range nodes[i].attributes() {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(&buf, attr[0])
+			buf.WriteByte(':')
+			writeJSONString(&buf, attr[1])
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err := out.WriteTo(w)
+	return err
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	// Marshalling a string can't fail.
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
@@ -0,0 +1,221 @@
+package rundeck
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Node is a node in a Rundeck resource model, as read from or written to a
+// resource model document such as those served by a URL resource source.
+type Node struct {
+	// Name is the unique name of the node within the project.
+	Name string
+
+	Description string
+
+	// Hostname is the address used to connect to the node, optionally
+	// followed by a colon and port number.
+	Hostname string
+
+	// Username is the user to connect to the node as.
+	Username string
+
+	OSFamily  string
+	OSArch    string
+	OSName    string
+	OSVersion string
+
+	Tags []string
+
+	// Attributes are any other attributes of the node, such as
+	// "ssh-key-storage-path". It must not include the standard attributes
+	// represented by the other fields.
+	Attributes map[string]string
+}
+
+// ResourceFormat is the name of a resource model document format.
+type ResourceFormat string
+
+// The resource model document formats supported by ParseNodes and
+// WriteNodes.
+const (
+	ResourceFormatXML  ResourceFormat = "resourcexml"
+	ResourceFormatYAML ResourceFormat = "resourceyaml"
+	ResourceFormatJSON ResourceFormat = "resourcejson"
+)
+
+// ContentType returns the MIME type of documents in the format, for use when
+// serving them to a URL resource source.
+func (f ResourceFormat) ContentType() string {
+	switch f {
+	case ResourceFormatXML:
+		return "application/xml"
+	case ResourceFormatYAML:
+		return "application/yaml"
+	case ResourceFormatJSON:
+		return "application/json"
+	default:
+		return ""
+	}
+}
+
+// ParseNodes reads a resource model document in the given format, returning
+// its nodes in the order they appear.
+func ParseNodes(r io.Reader, format ResourceFormat) ([]Node, error) {
+	var nodes []Node
+	var err error
+	switch format {
+	case ResourceFormatXML:
+		nodes, err = parseNodesXML(r)
+	case ResourceFormatYAML:
+		nodes, err = parseNodesYAML(r)
+	case ResourceFormatJSON:
+		nodes, err = parseNodesJSON(r)
+	default:
+		return nil, fmt.Errorf("unsupported resource format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s document: %s", format, err)
+	}
+	return nodes, nil
+}
+
+// WriteNodes writes a resource model document in the given format.
+func WriteNodes(w io.Writer, format ResourceFormat, nodes []Node) error {
+	seen := map[string]bool{}
+	for i := range nodes {
+		if err := nodes[i].validate(); err != nil {
+			return err
+		}
+		if seen[nodes[i].Name] {
+			return fmt.Errorf("duplicate node %s", nodes[i].Name)
+		}
+		seen[nodes[i].Name] = true
+	}
+
+	switch format {
+	case ResourceFormatXML:
+		return writeNodesXML(w, nodes)
+	case ResourceFormatYAML:
+		return writeNodesYAML(w, nodes)
+	case ResourceFormatJSON:
+		return writeNodesJSON(w, nodes)
+	default:
+		return fmt.Errorf("unsupported resource format %q", format)
+	}
+}
+
+// The names of the standard node attributes, in the order they are written.
+const (
+	nodeAttrName        = "nodename"
+	nodeAttrDescription = "description"
+	nodeAttrHostname    = "hostname"
+	nodeAttrUsername    = "username"
+	nodeAttrOSFamily    = "osFamily"
+	nodeAttrOSArch      = "osArch"
+	nodeAttrOSName      = "osName"
+	nodeAttrOSVersion   = "osVersion"
+	nodeAttrTags        = "tags"
+)
+
+// field returns a pointer to the field holding the standard attribute with
+// the given name, or nil if it isn't a standard string attribute.
+func (n *Node) field(attr string) *string {
+	switch attr {
+	case nodeAttrName:
+		return &n.Name
+	case nodeAttrDescription:
+		return &n.Description
+	case nodeAttrHostname:
+		return &n.Hostname
+	case nodeAttrUsername:
+		return &n.Username
+	case nodeAttrOSFamily:
+		return &n.OSFamily
+	case nodeAttrOSArch:
+		return &n.OSArch
+	case nodeAttrOSName:
+		return &n.OSName
+	case nodeAttrOSVersion:
+		return &n.OSVersion
+	}
+	return nil
+}
+
+// setAttribute sets a standard or custom attribute of the node from its
+// value in a document.
+func (n *Node) setAttribute(name, value string) {
+	if name == nodeAttrTags {
+		n.Tags = parseNodeTags(value)
+		return
+	}
+	if f := n.field(name); f != nil {
+		*f = value
+		return
+	}
+	if n.Attributes == nil {
+		n.Attributes = map[string]string{}
+	}
+	n.Attributes[name] = value
+}
+
+// attributes returns the non-empty attributes of the node, standard ones
+// first and then the others in sorted order, with the tags joined into a
+// single value.
+func (n *Node) attributes() [][2]string {
+	var attrs [][2]string
+	for _, name := range []string{nodeAttrName, nodeAttrDescription, nodeAttrHostname, nodeAttrUsername, nodeAttrOSFamily, nodeAttrOSArch, nodeAttrOSName, nodeAttrOSVersion} {
+		if v := *n.field(name); v != "" {
+			attrs = append(attrs, [2]string{name, v})
+		}
+	}
+	if len(n.Tags) > 0 {
+		attrs = append(attrs, [2]string{nodeAttrTags, strings.Join(n.Tags, ",")})
+	}
+	for _, name := range n.customAttributeNames() {
+		attrs = append(attrs, [2]string{name, n.Attributes[name]})
+	}
+	return attrs
+}
+
+func (n *Node) customAttributeNames() []string {
+	names := make([]string, 0, len(n.Attributes))
+	for name := range n.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (n *Node) validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("node has no name")
+	}
+	for name := range n.Attributes {
+		switch {
+		case name == "":
+			return fmt.Errorf("node %s has an attribute with no name", n.Name)
+		case name == nodeAttrTags || n.field(name) != nil:
+			return fmt.Errorf("node %s has standard attribute %s in Attributes", n.Name, name)
+		}
+	}
+	for _, tag := range n.Tags {
+		if tag == "" || strings.Contains(tag, ",") {
+			return fmt.Errorf("node %s has invalid tag %q", n.Name, tag)
+		}
+	}
+	return nil
+}
+
+// parseNodeTags splits a comma-separated list of tags.
+func parseNodeTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,332 @@
+package rundeck
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testNodes = []Node{
+	{
+		Name:        "web-1",
+		Description: "Web server: \"blue\" pool",
+		Hostname:    "10.0.0.1:2222",
+		Username:    "rundeck",
+		OSFamily:    "unix",
+		OSName:      "Linux",
+		Tags:        []string{"web", "prod"},
+		Attributes: map[string]string{
+			"ssh-key-storage-path": "keys/ops/ssh/id_rsa",
+			"rack":                 "true",
+			"notes":                "line one\nline two # not a comment",
+		},
+	},
+	{
+		Name:     "db/primary",
+		Hostname: "db.example.com",
+	},
+}
+
+func TestNodesRoundTrip(t *testing.T) {
+	for _, format := range []ResourceFormat{ResourceFormatXML, ResourceFormatYAML, ResourceFormatJSON} {
+		var buf bytes.Buffer
+		if err := WriteNodes(&buf, format, testNodes); err != nil {
+			t.Errorf("%s: error writing nodes: %s", format, err)
+			continue
+		}
+		got, err := ParseNodes(&buf, format)
+		if err != nil {
+			t.Errorf("%s: error parsing written nodes: %s", format, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, testNodes) {
+			t.Errorf("%s: got %#v, but wanted %#v", format, got, testNodes)
+		}
+	}
+}
+
+func TestParseNodes(t *testing.T) {
+	want := []Node{
+		{
+			Name:        "node1",
+			Description: "Rundeck server node",
+			Hostname:    "node1.example.com",
+			Username:    "rundeck",
+			OSFamily:    "unix",
+			OSArch:      "amd64",
+			Tags:        []string{"web", "prod"},
+			Attributes:  map[string]string{"region": "eu-west-1"},
+		},
+		{
+			Name:     "node2",
+			Hostname: "node2.example.com",
+			Tags:     []string{"db"},
+		},
+	}
+
+	tests := []struct {
+		Format ResourceFormat
+		Input  string
+	}{
+		{
+			ResourceFormatXML,
+			`<?xml version="1.0" encoding="UTF-8"?>
+<project>
+  <node name="node1" description="Rundeck server node" tags="web, prod"
+        hostname="node1.example.com" osArch="amd64" osFamily="unix"
+        username="rundeck" region="eu-west-1"/>
+  <node name="node2" hostname="node2.example.com">
+    <attribute name="tags" value="db"/>
+  </node>
+</project>`,
+		},
+		{
+			ResourceFormatYAML,
+			`# Generated from the CMDB
+node1:
+  nodename: node1
+  description: Rundeck server node
+  hostname: node1.example.com
+  osArch: amd64
+  osFamily: unix
+  username: rundeck
+  tags: 'web, prod'
+  region: "eu-west-1"  # primary region
+node2:
+  hostname: node2.example.com
+  tags:
+  - db
+`,
+		},
+		{
+			ResourceFormatYAML,
+			`---
+- nodename: node1
+  description: Rundeck server node
+  hostname: node1.example.com
+  osArch: amd64
+  osFamily: unix
+  username: rundeck
+  tags: [web, prod]
+  region: eu-west-1
+- nodename: node2
+  hostname: node2.example.com
+  tags: [db]
+`,
+		},
+		{
+			ResourceFormatJSON,
+			`{
+  "node1": {
+    "nodename": "node1",
+    "description": "Rundeck server node",
+    "hostname": "node1.example.com",
+    "osArch": "amd64",
+    "osFamily": "unix",
+    "username": "rundeck",
+    "tags": "web, prod",
+    "region": "eu-west-1"
+  },
+  "node2": {
+    "hostname": "node2.example.com",
+    "tags": ["db"]
+  }
+}`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := ParseNodes(strings.NewReader(test.Input), test.Format)
+		if err != nil {
+			t.Errorf("%d (%s): error parsing nodes: %s", i, test.Format, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d (%s): got %#v, but wanted %#v", i, test.Format, got, want)
+		}
+	}
+}
+
+func TestWriteNodesYAML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteNodes(&buf, ResourceFormatYAML, testNodes); err != nil {
+		t.Fatalf("error writing nodes: %s", err)
+	}
+	want := `web-1:
+  nodename: web-1
+  description: 'Web server: "blue" pool'
+  hostname: 10.0.0.1:2222
+  username: rundeck
+  osFamily: unix
+  osName: Linux
+  tags: web,prod
+  notes: |-
+    line one
+    line two # not a comment
+  rack: "true"
+  ssh-key-storage-path: keys/ops/ssh/id_rsa
+db/primary:
+  nodename: db/primary
+  hostname: db.example.com
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwanted:\n%s", got, want)
+	}
+}
+
+func TestWriteNodesErrors(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Nodes []Node
+		Error string
+	}{
+		{
+			"no-name",
+			[]Node{{Hostname: "example.com"}},
+			"node has no name",
+		},
+		{
+			"duplicate",
+			[]Node{{Name: "a"}, {Name: "a"}},
+			"duplicate node a",
+		},
+		{
+			"standard-attribute",
+			[]Node{{Name: "a", Attributes: map[string]string{"hostname": "example.com"}}},
+			"standard attribute hostname",
+		},
+		{
+			"bad-tag",
+			[]Node{{Name: "a", Tags: []string{"x,y"}}},
+			"invalid tag",
+		},
+	}
+
+	for _, test := range tests {
+		err := WriteNodes(&bytes.Buffer{}, ResourceFormatJSON, test.Nodes)
+		if err == nil {
+			t.Errorf("%s: got no error, but wanted one", test.Name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.Error) {
+			t.Errorf("%s: got error %q, but wanted it to contain %q", test.Name, err, test.Error)
+		}
+	}
+}
+
+func TestParseNodesWrappedYAML(t *testing.T) {
+	// Rundeck writes resourceyaml with SnakeYAML, which wraps long values
+	// at 80 columns.
+	f, err := os.Open("testdata/resources/rundeck-export.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := ParseNodes(f, ResourceFormatYAML)
+	if err != nil {
+		t.Fatalf("error parsing nodes: %s", err)
+	}
+	want := []Node{
+		{
+			Name:        "web-1.example.com",
+			Description: "Primary application server for the payments service, managed by the platform team and patched monthly",
+			Hostname:    "web-1.example.com",
+			Username:    "rundeck",
+			OSFamily:    "unix",
+			OSArch:      "amd64",
+			OSName:      "Linux",
+			OSVersion:   "3.10.0-1160.el7.x86_64",
+			Tags:        []string{"app", "payments", "prod"},
+			Attributes: map[string]string{
+				"maintenance": "Window: Sundays 02:00-04:00 UTC, contact the on-call engineer before any changes are made",
+				"motd":        "Welcome\tto the payments cluster. Please read the runbook before running any jobs against this node",
+			},
+		},
+		{
+			Name:     "db-1.example.com",
+			Hostname: "db-1.example.com",
+			Username: "postgres",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, but wanted %#v", got, want)
+	}
+}
+
+func TestParseNodesYAMLValues(t *testing.T) {
+	input := `web-1: {hostname: web-1.example.com, tags: [web, prod]}
+2:
+  hostname: 10.0.0.2
+  osVersion: 3.10
+  port: 0755
+  enabled: yes
+  notes: ~
+`
+	got, err := ParseNodes(strings.NewReader(input), ResourceFormatYAML)
+	if err != nil {
+		t.Fatalf("error parsing nodes: %s", err)
+	}
+	want := []Node{
+		{
+			Name:     "web-1",
+			Hostname: "web-1.example.com",
+			Tags:     []string{"web", "prod"},
+		},
+		{
+			Name:      "2",
+			Hostname:  "10.0.0.2",
+			OSVersion: "3.10",
+			Attributes: map[string]string{
+				"port":    "0755",
+				"enabled": "yes",
+				"notes":   "",
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, but wanted %#v", got, want)
+	}
+}
+
+func TestParseNodesYAMLErrors(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input string
+		Error string
+	}{
+		{
+			"scalar",
+			"just a string\n",
+			"document must be a mapping or sequence",
+		},
+		{
+			"nested",
+			"web-1:\n  tags:\n  - [a, b]\n",
+			"attribute values must be scalars or sequences of scalars",
+		},
+		{
+			"no-name",
+			"- hostname: example.com\n",
+			"node 1 has no name",
+		},
+		{
+			"syntax",
+			"a: [b\n",
+			"yaml:",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseNodes(strings.NewReader(test.Input), ResourceFormatYAML)
+		if err == nil {
+			t.Errorf("%s: got no error, but wanted one", test.Name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.Error) {
+			t.Errorf("%s: got error %q, but wanted it to contain %q", test.Name, err, test.Error)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package rundeck
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResourceSource is a resource model source, which provides nodes to a
+// project.
+type ResourceSource struct {
+	// Type is the name of the resource model source plugin, such as
+	// ResourceSourceFile.
+	Type string
+
+	// Config is the plugin-specific configuration of the source.
+	Config map[string]string
+}
+
+// The types of the resource model sources built in to Rundeck.
+const (
+	// ResourceSourceFile reads nodes from a file on the Rundeck server. Its
+	// configuration includes "file" and "format", such as "resourcexml".
+	ResourceSourceFile = "file"
+
+	// ResourceSourceURL fetches nodes from a URL, given as "url".
+	ResourceSourceURL = "url"
+
+	// ResourceSourceDirectory reads nodes from all of the files in the
+	// directory given as "directory".
+	ResourceSourceDirectory = "directory"
+
+	// ResourceSourceScript runs the script given as "file" and reads nodes
+	// from its output.
+	ResourceSourceScript = "script"
+)
+
+func (s *ResourceSource) validate() error {
+	if s.Type == "" {
+		return fmt.Errorf("resource source has no type")
+	}
+	for k := range s.Config {
+		if k == "" {
+			return fmt.Errorf("%s resource source has an empty config key", s.Type)
+		}
+	}
+	return nil
+}
+
+// parseResourceSources decodes the resources.source.N.* keys, returning the
+// sources in order of N. The numbers need not be contiguous.
+func parseResourceSources(config ProjectConfig) ([]ResourceSource, error) {
+	byIndex := map[int]*ResourceSource{}
+	for key, value := range config {
+		if !strings.HasPrefix(key, projectConfigResourceSourcePrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, projectConfigResourceSourcePrefix)
+		dot := strings.IndexByte(rest, '.')
+		if dot < 0 {
+			return nil, fmt.Errorf("invalid resource source key %s", key)
+		}
+		index, err := strconv.Atoi(rest[:dot])
+		if err != nil || index < 1 {
+			return nil, fmt.Errorf("invalid resource source key %s: %q is not a positive number", key, rest[:dot])
+		}
+
+		source := byIndex[index]
+		if source == nil {
+			source = &ResourceSource{Config: map[string]string{}}
+			byIndex[index] = source
+		}
+
+		switch prop := rest[dot+1:]; {
+		case prop == "type":
+			source.Type = value
+		case strings.HasPrefix(prop, "config.") && len(prop) > len("config."):
+			source.Config[strings.TrimPrefix(prop, "config.")] = value
+		default:
+			return nil, fmt.Errorf("unrecognized resource source key %s", key)
+		}
+	}
+
+	indexes := make([]int, 0, len(byIndex))
+	for index := range byIndex {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	var sources []ResourceSource
+	for _, index := range indexes {
+		source := byIndex[index]
+		if source.Type == "" {
+			return nil, fmt.Errorf("resource source %d has no type", index)
+		}
+		sources = append(sources, *source)
+	}
+	return sources, nil
+}
+
+// setResourceSourcesConfig adds the resources.source.N.* keys representing
+// sources to config, numbering them from 1.
+func setResourceSourcesConfig(config ProjectConfig, sources []ResourceSource) {
+	for i, source := range sources {
+		prefix := projectConfigResourceSourcePrefix + strconv.Itoa(i+1) + "."
+		config[prefix+"type"] = source.Type
+		for k, v := range source.Config {
+			config[prefix+"config."+k] = v
+		}
+	}
+}
+
+// ListResourceSources returns the resource model sources of the named
+// project, in the order that Rundeck consults them. The index of each source
+// in the list, plus one, is its number in the project configuration and is
+// used to identify it to RemoveResourceSource and ReorderResourceSources.
+func (c *Client) ListResourceSources(projectName string) ([]ResourceSource, error) {
+	config, err := c.GetProjectConfig(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return parseResourceSources(config)
+}
+
+// SetResourceSources replaces all of the resource model sources of the named
+// project, leaving the rest of its configuration unchanged.
+//
+// This and the other functions that change resource sources write only the
+// resources.source.N.* keys that differ, one at a time, in an order that
+// never leaves Rundeck with a partly-updated source. If an error is returned,
+// some sources may be left without a type, so that Rundeck ignores them;
+// SetResourceSources can then be used to write the sources again.
+func (c *Client) SetResourceSources(projectName string, sources []ResourceSource) error {
+	for i := range sources {
+		if err := sources[i].validate(); err != nil {
+			return err
+		}
+	}
+	// The current sources aren't parsed, so that sources left without a
+	// type by an earlier failure can be replaced.
+	config, err := c.GetProjectConfig(projectName)
+	if err != nil {
+		return err
+	}
+	return c.writeResourceSources(projectName, config, sources)
+}
+
+// AddResourceSource adds a resource model source after the existing sources
+// of the named project, and returns its number.
+func (c *Client) AddResourceSource(projectName string, source ResourceSource) (int, error) {
+	var number int
+	err := c.editResourceSources(projectName, func(sources []ResourceSource) ([]ResourceSource, error) {
+		number = len(sources) + 1
+		return append(sources, source), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+// RemoveResourceSource removes the resource model source with the given
+// number from the named project. Later sources are renumbered to fill the
+// gap.
+func (c *Client) RemoveResourceSource(projectName string, number int) error {
+	return c.editResourceSources(projectName, func(sources []ResourceSource) ([]ResourceSource, error) {
+		if number < 1 || number > len(sources) {
+			return nil, fmt.Errorf("project %s has no resource source %d", projectName, number)
+		}
+		return append(sources[:number-1], sources[number:]...), nil
+	})
+}
+
+// ReorderResourceSources changes the order of the resource model sources of
+// the named project. order lists the current number of each source in its
+// new position, and must include every source exactly once. For example,
+// with three sources, []int{3, 1, 2} moves the last source to the front.
+func (c *Client) ReorderResourceSources(projectName string, order []int) error {
+	return c.editResourceSources(projectName, func(sources []ResourceSource) ([]ResourceSource, error) {
+		if len(order) != len(sources) {
+			return nil, fmt.Errorf("order has %d entries, but project %s has %d resource sources", len(order), projectName, len(sources))
+		}
+		seen := make([]bool, len(sources))
+		reordered := make([]ResourceSource, 0, len(sources))
+		for _, number := range order {
+			if number < 1 || number > len(sources) {
+				return nil, fmt.Errorf("project %s has no resource source %d", projectName, number)
+			}
+			if seen[number-1] {
+				return nil, fmt.Errorf("resource source %d appears more than once in order", number)
+			}
+			seen[number-1] = true
+			reordered = append(reordered, sources[number-1])
+		}
+		return reordered, nil
+	})
+}
+
+// editResourceSources rewrites the resources.source.N.* keys of the named
+// project to represent the sources returned by edit, which is given the
+// current sources.
+func (c *Client) editResourceSources(projectName string, edit func([]ResourceSource) ([]ResourceSource, error)) error {
+	config, err := c.GetProjectConfig(projectName)
+	if err != nil {
+		return err
+	}
+	sources, err := parseResourceSources(config)
+	if err != nil {
+		return err
+	}
+	if sources, err = edit(sources); err != nil {
+		return err
+	}
+	for i := range sources {
+		if err := sources[i].validate(); err != nil {
+			return err
+		}
+	}
+	return c.writeResourceSources(projectName, config, sources)
+}
+
+// writeResourceSources makes the writes needed to change the
+// resources.source.N.* keys of a project whose configuration is currently
+// current so that they represent sources. Other keys are left unchanged.
+//
+// Rundeck ignores a source that has no type key, so the type of every
+// source that changes is removed first, from the last source to the first.
+// The other keys of those sources are written next, and the new types are
+// set last, from the first source to the last. Rundeck therefore sees each
+// source either as it was or as it will be, never a mix of the two.
+func (c *Client) writeResourceSources(projectName string, current ProjectConfig, sources []ResourceSource) error {
+	want := ProjectConfig{}
+	setResourceSourcesConfig(want, sources)
+
+	// Group the keys of both configurations by the source they belong to,
+	// identified by a prefix such as "resources.source.2.", and find the
+	// sources that change.
+	keys := map[string]bool{}
+	for _, config := range []ProjectConfig{current, want} {
+		for k := range config {
+			if strings.HasPrefix(k, projectConfigResourceSourcePrefix) {
+				keys[k] = true
+			}
+		}
+	}
+	groups := map[string][]string{}
+	var changed []string
+	for k := range keys {
+		prefix := resourceSourceKeyPrefix(k)
+		groups[prefix] = append(groups[prefix], k)
+	}
+	for prefix, group := range groups {
+		sort.Strings(group)
+		for _, k := range group {
+			cv, inCurrent := current[k]
+			wv, inWant := want[k]
+			if inCurrent != inWant || cv != wv {
+				changed = append(changed, prefix)
+				break
+			}
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return resourceSourceKeyLess(changed[i], changed[j])
+	})
+
+	for i := len(changed) - 1; i >= 0; i-- {
+		typeKey := changed[i] + "type"
+		if _, ok := current[typeKey]; ok {
+			if err := c.DeleteProjectConfigKey(projectName, typeKey); err != nil {
+				return fmt.Errorf("error removing %s: %s", typeKey, err)
+			}
+		}
+	}
+	for _, prefix := range changed {
+		for _, k := range groups[prefix] {
+			cv, inCurrent := current[k]
+			wv, inWant := want[k]
+			switch {
+			case k == prefix+"type":
+				continue
+			case !inWant && inCurrent:
+				if err := c.DeleteProjectConfigKey(projectName, k); err != nil {
+					return fmt.Errorf("error removing %s: %s", k, err)
+				}
+			case inWant && (!inCurrent || cv != wv):
+				if err := c.SetProjectConfigKey(projectName, k, wv); err != nil {
+					return fmt.Errorf("error setting %s: %s", k, err)
+				}
+			}
+		}
+	}
+	for _, prefix := range changed {
+		typeKey := prefix + "type"
+		if v, ok := want[typeKey]; ok {
+			if err := c.SetProjectConfigKey(projectName, typeKey, v); err != nil {
+				return fmt.Errorf("error setting %s: %s", typeKey, err)
+			}
+		}
+	}
+	return nil
+}
+
+// resourceSourceKeyPrefix returns the prefix of a resources.source.N.* key
+// that identifies its source, such as "resources.source.2.". A key with no
+// property is its own prefix.
+func resourceSourceKeyPrefix(key string) string {
+	rest := strings.TrimPrefix(key, projectConfigResourceSourcePrefix)
+	dot := strings.IndexByte(rest, '.')
+	if dot < 0 {
+		return key
+	}
+	return projectConfigResourceSourcePrefix + rest[:dot+1]
+}
+
+// resourceSourceKeyLess orders source prefixes by number, with any that
+// aren't numbered last.
+func resourceSourceKeyLess(a, b string) bool {
+	number := func(prefix string) int {
+		rest := strings.TrimSuffix(strings.TrimPrefix(prefix, projectConfigResourceSourcePrefix), ".")
+		n, err := strconv.Atoi(rest)
+		if err != nil {
+			return math.MaxInt
+		}
+		return n
+	}
+	if na, nb := number(a), number(b); na != nb {
+		return na < nb
+	}
+	return a < b
+}
@@ -0,0 +1,131 @@
+package rundeck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseResourceSources(t *testing.T) {
+	config := ProjectConfig{
+		"project.name":                      "example",
+		"resources.source.10.type":          "url",
+		"resources.source.10.config.url":    "https://cmdb.example.com/nodes.xml",
+		"resources.source.2.type":           "file",
+		"resources.source.2.config.file":    "/tmp/nodes.xml",
+		"resources.source.2.config.format":  "resourcexml",
+		"resources.source.5.type":           "directory",
+		"resources.source.5.config.dir.sub": "nested key",
+	}
+	got, err := parseResourceSources(config)
+	if err != nil {
+		t.Fatalf("error parsing sources: %s", err)
+	}
+
+	// Gaps in the numbering are closed up, and the numbers are compared as
+	// numbers rather than strings.
+	want := []ResourceSource{
+		{Type: "file", Config: map[string]string{"file": "/tmp/nodes.xml", "format": "resourcexml"}},
+		{Type: "directory", Config: map[string]string{"dir.sub": "nested key"}},
+		{Type: "url", Config: map[string]string{"url": "https://cmdb.example.com/nodes.xml"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, but wanted %#v", got, want)
+	}
+
+	if got, err := parseResourceSources(ProjectConfig{"project.name": "example"}); err != nil || got != nil {
+		t.Errorf("got %#v, %v for a project without sources, but wanted nil", got, err)
+	}
+}
+
+func TestParseResourceSourcesErrors(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Config ProjectConfig
+		Error  string
+	}{
+		{
+			"no-property",
+			ProjectConfig{"resources.source.1": "file"},
+			"invalid resource source key",
+		},
+		{
+			"bad-index",
+			ProjectConfig{"resources.source.one.type": "file"},
+			`"one" is not a positive number`,
+		},
+		{
+			"negative-index",
+			ProjectConfig{"resources.source.-1.type": "file"},
+			`"-1" is not a positive number`,
+		},
+		{
+			"missing-type",
+			ProjectConfig{
+				"resources.source.1.type":        "file",
+				"resources.source.3.config.file": "/tmp/nodes.xml",
+			},
+			"resource source 3 has no type",
+		},
+		{
+			"empty-config-key",
+			ProjectConfig{
+				"resources.source.1.type":    "file",
+				"resources.source.1.config.": "x",
+			},
+			"unrecognized resource source key",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := parseResourceSources(test.Config)
+		if err == nil {
+			t.Errorf("%s: got no error, but wanted one", test.Name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.Error) {
+			t.Errorf("%s: got error %q, but wanted it to contain %q", test.Name, err, test.Error)
+		}
+	}
+}
+
+func TestSetResourceSourcesConfig(t *testing.T) {
+	sources := []ResourceSource{
+		{Type: "url", Config: map[string]string{"url": "https://cmdb.example.com/nodes.xml", "cache": "true"}},
+		{Type: "directory"},
+	}
+	config := ProjectConfig{"project.name": "example"}
+	setResourceSourcesConfig(config, sources)
+
+	want := ProjectConfig{
+		"project.name":                    "example",
+		"resources.source.1.type":         "url",
+		"resources.source.1.config.url":   "https://cmdb.example.com/nodes.xml",
+		"resources.source.1.config.cache": "true",
+		"resources.source.2.type":         "directory",
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("got %#v, but wanted %#v", config, want)
+	}
+
+	got, err := parseResourceSources(config)
+	if err != nil {
+		t.Fatalf("error parsing written sources: %s", err)
+	}
+	sources[1].Config = map[string]string{}
+	if !reflect.DeepEqual(got, sources) {
+		t.Errorf("got %#v back, but wanted %#v", got, sources)
+	}
+}
+
+func TestResourceSourceValidate(t *testing.T) {
+	if err := (&ResourceSource{Type: "file"}).validate(); err != nil {
+		t.Errorf("valid source rejected: %s", err)
+	}
+	if err := (&ResourceSource{}).validate(); err == nil {
+		t.Errorf("source with no type accepted; want error")
+	}
+	if err := (&ResourceSource{Type: "file", Config: map[string]string{"": "x"}}).validate(); err == nil {
+		t.Errorf("source with an empty config key accepted; want error")
+	}
+}
@@ -0,0 +1,93 @@
+package rundeck
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// resourceXMLDocument is the root of a resourcexml document. The standard
+// node attributes are XML attributes of each node element, while other
+// attributes may be either XML attributes or attribute child elements.
+type resourceXMLDocument struct {
+	XMLName xml.Name          `xml:"project"`
+	Nodes   []resourceXMLNode `xml:"node"`
+}
+
+type resourceXMLNode struct {
+	Attrs      []xml.Attr             `xml:",any,attr"`
+	Attributes []resourceXMLAttribute `xml:"attribute"`
+}
+
+type resourceXMLAttribute struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// resourceXMLName is the XML attribute used for the node name, in place of
+// nodename.
+const resourceXMLName = "name"
+
+func parseNodesXML(r io.Reader) ([]Node, error) {
+	var doc resourceXMLDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	nodes := make([]Node, 0, len(doc.Nodes))
+	for i, xmlNode := range doc.Nodes {
+		var node Node
+		for _, attr := range xmlNode.Attrs {
+			name := attr.Name.Local
+			if attr.Name.Space != "" {
+				continue
+			}
+			if name == resourceXMLName {
+				name = nodeAttrName
+			}
+			node.setAttribute(name, attr.Value)
+		}
+		for _, attr := range xmlNode.Attributes {
+			if attr.Name == "" {
+				return nil, fmt.Errorf("node %d has an attribute element with no name", i+1)
+			}
+			node.setAttribute(attr.Name, attr.Value)
+		}
+		if node.Name == "" {
+			return nil, fmt.Errorf("node %d has no name", i+1)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func writeNodesXML(w io.Writer, nodes []Node) error {
+	doc := resourceXMLDocument{}
+	for i := range nodes {
+		node := &nodes[i]
+		var xmlNode resourceXMLNode
+		for _, attr := range node.attributes() {
+			name, value := attr[0], attr[1]
+			if _, custom := node.Attributes[name]; custom {
+				xmlNode.Attributes = append(xmlNode.Attributes, resourceXMLAttribute{name, value})
+				continue
+			}
+			if name == nodeAttrName {
+				name = resourceXMLName
+			}
+			xmlNode.Attrs = append(xmlNode.Attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+		}
+		doc.Nodes = append(doc.Nodes, xmlNode)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
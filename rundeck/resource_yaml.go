@@ -0,0 +1,109 @@
+package rundeck
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// yamlNodeAttributes are the attributes of a node in a resourceyaml
+// document.
+type yamlNodeAttributes map[string]yamlAttributeValue
+
+// yamlAttributeValue is an attribute value from a resourceyaml document.
+// Scalars keep the text they were written with, so that values such as
+// "3.10" aren't changed by being read as numbers, and sequences are joined
+// with commas, as used for tags.
+type yamlAttributeValue string
+
+func (v *yamlAttributeValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*v = yamlAttributeValue(s)
+		return nil
+	}
+	var items []string
+	if err := unmarshal(&items); err != nil {
+		return fmt.Errorf("attribute values must be scalars or sequences of scalars")
+	}
+	*v = yamlAttributeValue(strings.Join(items, ","))
+	return nil
+}
+
+func parseNodesYAML(r io.Reader) ([]Node, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	// A resourceyaml document is usually a mapping from node names to
+	// attributes, but may also be a sequence of attribute mappings.
+	var names []string
+	var attrs []yamlNodeAttributes
+	switch doc.(type) {
+	case nil:
+	case map[interface{}]interface{}:
+		// A MapSlice keeps the order of the nodes, but not the text of
+		// their attributes, so the document is decoded twice.
+		var order yaml.MapSlice
+		if err := yaml.Unmarshal(data, &order); err != nil {
+			return nil, err
+		}
+		var byName map[interface{}]yamlNodeAttributes
+		if err := yaml.Unmarshal(data, &byName); err != nil {
+			return nil, err
+		}
+		for _, item := range order {
+			name, ok := item.Key.(string)
+			if !ok && item.Key != nil {
+				name = fmt.Sprint(item.Key)
+			}
+			names = append(names, name)
+			attrs = append(attrs, byName[item.Key])
+		}
+	case []interface{}:
+		if err := yaml.Unmarshal(data, &attrs); err != nil {
+			return nil, err
+		}
+		names = make([]string, len(attrs))
+	default:
+		return nil, fmt.Errorf("document must be a mapping or sequence")
+	}
+
+	nodes := make([]Node, 0, len(attrs))
+	for i := range attrs {
+		node := Node{Name: names[i]}
+		for name, value := range attrs[i] {
+			node.setAttribute(name, string(value))
+		}
+		if node.Name == "" {
+			return nil, fmt.Errorf("node %d has no name", i+1)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func writeNodesYAML(w io.Writer, nodes []Node) error {
+	doc := make(yaml.MapSlice, 0, len(nodes))
+	for i := range nodes {
+		var attrs yaml.MapSlice
+		for _, attr := range nodes[i].attributes() {
+			attrs = append(attrs, yaml.MapItem{Key: attr[0], Value: attr[1]})
+		}
+		doc = append(doc, yaml.MapItem{Key: nodes[i].Name, Value: attrs})
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
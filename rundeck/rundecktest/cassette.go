@@ -0,0 +1,272 @@
+package rundecktest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted is the placeholder that replaces secret values in recordings.
+const Redacted = "[REDACTED]"
+
+// Cassette is an http.RoundTripper that records HTTP interactions to a file
+// and later replays them, so that tests of code using rundeck.Client can run
+// deterministically without a Rundeck server.
+//
+// To use a cassette, set it as the Transport in the rundeck.ClientConfig
+// passed to rundeck.NewClient. A cassette created with RecordCassette passes
+// requests through to a real server and remembers them until Save is called.
+// A cassette created with LoadCassette answers requests from a previous
+// recording, failing any request that was not recorded.
+//
+// Requests are matched by method, path, query string and body. Query
+// arguments are compared without regard to their order, and multipart
+// bodies are compared by their fields rather than their exact encoding.
+// When several recorded interactions match a request, they are replayed in
+// the order they were recorded.
+//
+// Recordings never contain the X-Rundeck-Auth-Token header, nor the content
+// of private keys and passwords sent to key storage.
+type Cassette struct {
+	path      string
+	recording bool
+	transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+
+	replayed bool
+}
+
+// RecordedRequest is the part of a request that is used to match it with
+// later requests during replay.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response that will be returned during replay.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// RecordCassette returns a cassette that sends requests using the given
+// transport and records them to be written to the given path by Save. If
+// transport is nil, http.DefaultTransport is used.
+func RecordCassette(path string, transport http.RoundTripper) *Cassette {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Cassette{
+		path:      path,
+		recording: true,
+		transport: transport,
+	}
+}
+
+// LoadCassette returns a cassette that replays the interactions previously
+// saved at the given path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{
+		path: path,
+	}
+	err = json.Unmarshal(data, &c.interactions)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding cassette %s: %s", path, err.Error())
+	}
+	return c, nil
+}
+
+// Interactions returns the interactions recorded or loaded so far.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ret := make([]Interaction, len(c.interactions))
+	for i, interaction := range c.interactions {
+		ret[i] = *interaction
+	}
+	return ret
+}
+
+// Save writes the recorded interactions to the cassette's file. It has no
+// effect for a cassette that is replaying.
+func (c *Cassette) Save() error {
+	if !c.recording {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(data, '\n'), 0644)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+	recorded, err := recordRequest(req, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.recording {
+		return c.record(req, recorded)
+	}
+	return c.replay(req, recorded)
+}
+
+func (c *Cassette) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	res, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	header := http.Header{}
+	for _, name := range []string{"Content-Type", "ETag", "Last-Modified"} {
+		if v := res.Header.Get(name); v != "" {
+			header.Set(name, v)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     header,
+			Body:       string(resBody),
+		},
+	})
+	return res, nil
+}
+
+func (c *Cassette) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, interaction := range c.interactions {
+		if interaction.replayed || interaction.Request != recorded {
+			continue
+		}
+		interaction.replayed = true
+
+		body := []byte(interaction.Response.Body)
+		header := http.Header{}
+		for k, v := range interaction.Response.Header {
+			header[k] = v
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s has no unreplayed interaction for %s %s", c.path, recorded.Method, recorded.Path)
+}
+
+// recordRequest produces the normalized, scrubbed form of a request that is
+// both saved in recordings and used for matching.
+func recordRequest(req *http.Request, body []byte) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.EscapedPath(),
+		// Encode sorts by key, which normalizes the argument order.
+		Query: req.URL.Query().Encode(),
+	}
+
+	if len(body) == 0 {
+		return recorded, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch {
+	case isSecretKeyRequest(req, mediaType):
+		recorded.Body = Redacted
+	case strings.HasPrefix(mediaType, "multipart/"):
+		normalized, err := normalizeMultipart(body, params["boundary"])
+		if err != nil {
+			return recorded, err
+		}
+		recorded.Body = normalized
+	default:
+		recorded.Body = strings.TrimSpace(string(body))
+	}
+	return recorded, nil
+}
+
+// isSecretKeyRequest returns true if the request is storing a private key or
+// password, whose content must never be written to a recording.
+func isSecretKeyRequest(req *http.Request, mediaType string) bool {
+	if !strings.Contains(req.URL.Path, "/storage/keys") {
+		return false
+	}
+	return mediaType == "application/octet-stream" || mediaType == "application/x-rundeck-data-password"
+}
+
+// normalizeMultipart renders a multipart body as its fields, sorted by name,
+// so that it doesn't depend on the random boundary or the field order.
+func normalizeMultipart(body []byte, boundary string) (string, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	fields := []string{}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", fmt.Errorf("error reading multipart body: %s", err.Error())
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, fmt.Sprintf("%s=%s", part.FormName(), strings.TrimSpace(string(value))))
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, "\n"), nil
+}
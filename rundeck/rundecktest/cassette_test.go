@@ -0,0 +1,92 @@
+package rundecktest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+func TestCassetteRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rundecktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	// exercise makes the same sequence of calls during both recording
+	// and replay, returning the id of the job it creates.
+	exercise := func(client *rundeck.Client) string {
+		job, err := rundeck.NewJob("example", "hello").Exec("echo hello").Build()
+		if err != nil {
+			t.Fatalf("error building job: %s", err)
+		}
+		summary, err := client.CreateJob(job)
+		if err != nil {
+			t.Fatalf("error creating job: %s", err)
+		}
+		if err := client.CreatePassword("db/password", "hunter2"); err != nil {
+			t.Fatalf("error creating password: %s", err)
+		}
+		got, err := client.GetJob(summary.ID)
+		if err != nil {
+			t.Fatalf("error getting job: %s", err)
+		}
+		if got.Name != "hello" {
+			t.Errorf("got job name %q, but wanted hello", got.Name)
+		}
+		if _, err := client.GetJobsForProject("example"); err != nil {
+			t.Fatalf("error exporting jobs: %s", err)
+		}
+		return summary.ID
+	}
+
+	server := NewServer()
+	server.AddProject(rundeck.Project{Name: "example"})
+	recorder := RecordCassette(path, nil)
+	config := server.ClientConfig()
+	config.Transport = recorder
+	client, err := rundeck.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordedID := exercise(client)
+	server.Close()
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("error saving cassette: %s", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{DefaultAuthToken, "hunter2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("recording contains secret %q", secret)
+		}
+	}
+
+	player, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("error loading cassette: %s", err)
+	}
+	client, err = rundeck.NewClient(&rundeck.ClientConfig{
+		BaseURL:   "http://rundeck.invalid/",
+		AuthToken: "another-token",
+		Transport: player,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayedID := exercise(client); replayedID != recordedID {
+		t.Errorf("replay created job %s, but recording created %s", replayedID, recordedID)
+	}
+
+	if _, err := client.GetAllProjects(); err == nil {
+		t.Errorf("unrecorded request succeeded; want error")
+	}
+}
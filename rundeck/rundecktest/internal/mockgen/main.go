@@ -0,0 +1,325 @@
+// Command mockgen generates the MockClient type in package rundecktest from
+// the API interface declared in package rundeck.
+//
+// It is run via "go generate" in the rundecktest package, and should be
+// re-run whenever the service interfaces change:
+//
+//	go run ./internal/mockgen -source ../services.go -out mock_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const rundeckImportPath = "github.com/apparentlymart/go-rundeck-api/rundeck"
+
+type method struct {
+	Name    string
+	Params  []param
+	Results []string
+}
+
+type param struct {
+	Name     string
+	Type     string
+	Variadic bool
+}
+
+type generator struct {
+	interfaces map[string]*ast.InterfaceType
+	imports    map[string]string
+	usedPkgs   map[string]bool
+	seen       map[string]bool
+	methods    []method
+}
+
+func main() {
+	source := flag.String("source", "../services.go", "file declaring the service interfaces")
+	out := flag.String("out", "mock_gen.go", "file to write the generated mock to")
+	iface := flag.String("interface", "API", "name of the interface to mock")
+	flag.Parse()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, *source, nil, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &generator{
+		interfaces: map[string]*ast.InterfaceType{},
+		imports:    map[string]string{},
+		usedPkgs:   map[string]bool{},
+		seen:       map[string]bool{},
+	}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		g.imports[name] = path
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok {
+			if it, ok := spec.Type.(*ast.InterfaceType); ok {
+				g.interfaces[spec.Name.Name] = it
+			}
+		}
+		return true
+	})
+
+	if err := g.collect(*iface); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := format.Source(g.render(*source))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// collect gathers the methods of the named interface, including those of
+// any interfaces embedded in it, in declaration order.
+func (g *generator) collect(name string) error {
+	it, ok := g.interfaces[name]
+	if !ok {
+		return fmt.Errorf("no interface named %s", name)
+	}
+	for _, field := range it.Methods.List {
+		switch t := field.Type.(type) {
+		case *ast.Ident:
+			if err := g.collect(t.Name); err != nil {
+				return err
+			}
+		case *ast.FuncType:
+			for _, n := range field.Names {
+				if g.seen[n.Name] {
+					continue
+				}
+				g.seen[n.Name] = true
+				g.methods = append(g.methods, g.method(n.Name, t))
+			}
+		default:
+			return fmt.Errorf("unsupported interface element in %s", name)
+		}
+	}
+	return nil
+}
+
+func (g *generator) method(name string, ft *ast.FuncType) method {
+	m := method{Name: name}
+	i := 0
+	for _, field := range ft.Params.List {
+		typ := field.Type
+		variadic := false
+		if ell, ok := typ.(*ast.Ellipsis); ok {
+			typ = ell.Elt
+			variadic = true
+		}
+		names := field.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+		for _, n := range names {
+			p := param{
+				Name:     fmt.Sprintf("arg%d", i),
+				Type:     g.typeString(typ),
+				Variadic: variadic,
+			}
+			if n != nil && n.Name != "_" {
+				p.Name = n.Name
+			}
+			m.Params = append(m.Params, p)
+			i++
+		}
+	}
+	if ft.Results != nil {
+		for _, field := range ft.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+			for j := 0; j < count; j++ {
+				m.Results = append(m.Results, g.typeString(field.Type))
+			}
+		}
+	}
+	return m
+}
+
+// typeString renders a type expression from the rundeck package so that it
+// can be used from package rundecktest, qualifying the rundeck package's own
+// exported types.
+func (g *generator) typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			g.usedPkgs["rundeck"] = true
+			return "rundeck." + t.Name
+		}
+		return t.Name
+	case *ast.SelectorExpr:
+		pkg := t.X.(*ast.Ident).Name
+		g.usedPkgs[pkg] = true
+		return pkg + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + g.typeString(t.X)
+	case *ast.ArrayType:
+		if t.Len != nil {
+			panic("array types are not supported")
+		}
+		return "[]" + g.typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + g.typeString(t.Key) + "]" + g.typeString(t.Value)
+	case *ast.Ellipsis:
+		return "..." + g.typeString(t.Elt)
+	case *ast.InterfaceType:
+		if len(t.Methods.List) != 0 {
+			panic("non-empty interface literals are not supported")
+		}
+		return "interface{}"
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + g.typeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + g.typeString(t.Value)
+		default:
+			return "chan " + g.typeString(t.Value)
+		}
+	case *ast.FuncType:
+		m := g.method("", t)
+		return "func" + m.signature()
+	default:
+		panic(fmt.Sprintf("unsupported type expression %T", expr))
+	}
+}
+
+func (m method) signature() string {
+	params := make([]string, len(m.Params))
+	for i, p := range m.Params {
+		if p.Variadic {
+			params[i] = p.Name + " ..." + p.Type
+		} else {
+			params[i] = p.Name + " " + p.Type
+		}
+	}
+	sig := "(" + strings.Join(params, ", ") + ")"
+	switch len(m.Results) {
+	case 0:
+	case 1:
+		sig += " " + m.Results[0]
+	default:
+		sig += " (" + strings.Join(m.Results, ", ") + ")"
+	}
+	return sig
+}
+
+func (g *generator) render(source string) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by mockgen from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(buf, "package rundecktest\n\n")
+
+	paths := []string{}
+	for pkg := range g.usedPkgs {
+		if pkg == "rundeck" {
+			paths = append(paths, rundeckImportPath)
+		} else {
+			paths = append(paths, g.imports[pkg])
+		}
+	}
+	sort.Strings(paths)
+	fmt.Fprintf(buf, "import (\n")
+	// Standard library packages come first, in a group of their own.
+	for _, std := range []bool{true, false} {
+		for _, path := range paths {
+			if isStdlib(path) == std {
+				fmt.Fprintf(buf, "\t%q\n", path)
+			}
+		}
+		if std {
+			fmt.Fprintf(buf, "\n")
+		}
+	}
+	fmt.Fprintf(buf, ")\n\n")
+
+	fmt.Fprintf(buf, "// MockClient is an implementation of rundeck.API for use in tests.\n")
+	fmt.Fprintf(buf, "//\n")
+	fmt.Fprintf(buf, "// Each method records its call and then calls the corresponding function\n")
+	fmt.Fprintf(buf, "// field, such as GetJobFunc for GetJob. If the function field is nil, the\n")
+	fmt.Fprintf(buf, "// method returns zero values and an error wrapping ErrNotMocked.\n")
+	fmt.Fprintf(buf, "type MockClient struct {\n")
+	for _, m := range g.methods {
+		fmt.Fprintf(buf, "\t%sFunc func%s\n", m.Name, m.signature())
+	}
+	fmt.Fprintf(buf, "\n\tmockRecorder\n")
+	fmt.Fprintf(buf, "}\n\n")
+	fmt.Fprintf(buf, "var _ rundeck.API = (*MockClient)(nil)\n")
+
+	for _, m := range g.methods {
+		args := make([]string, len(m.Params))
+		for i, p := range m.Params {
+			args[i] = p.Name
+			if p.Variadic {
+				args[i] += "..."
+			}
+		}
+		recordArgs := append([]string{fmt.Sprintf("%q", m.Name)}, names(m.Params)...)
+
+		fmt.Fprintf(buf, "\n// %s calls %sFunc.\n", m.Name, m.Name)
+		fmt.Fprintf(buf, "func (m *MockClient) %s%s {\n", m.Name, m.signature())
+		fmt.Fprintf(buf, "\tm.record(%s)\n", strings.Join(recordArgs, ", "))
+		fmt.Fprintf(buf, "\tif m.%sFunc == nil {\n", m.Name)
+		zeros := make([]string, len(m.Results))
+		for i, r := range m.Results {
+			if i == len(m.Results)-1 && r == "error" {
+				zeros[i] = fmt.Sprintf("notMocked(%q)", m.Name)
+				continue
+			}
+			fmt.Fprintf(buf, "\t\tvar r%d %s\n", i, r)
+			zeros[i] = fmt.Sprintf("r%d", i)
+		}
+		if len(zeros) > 0 {
+			fmt.Fprintf(buf, "\t\treturn %s\n", strings.Join(zeros, ", "))
+		} else {
+			fmt.Fprintf(buf, "\t\treturn\n")
+		}
+		fmt.Fprintf(buf, "\t}\n")
+		call := fmt.Sprintf("m.%sFunc(%s)", m.Name, strings.Join(args, ", "))
+		if len(m.Results) > 0 {
+			fmt.Fprintf(buf, "\treturn %s\n", call)
+		} else {
+			fmt.Fprintf(buf, "\t%s\n", call)
+		}
+		fmt.Fprintf(buf, "}\n")
+	}
+	return buf.Bytes()
+}
+
+func names(params []param) []string {
+	ret := make([]string, len(params))
+	for i, p := range params {
+		ret[i] = p.Name
+	}
+	return ret
+}
+
+// isStdlib returns true if the given import path is for a standard library
+// package, which is the case when its first element has no dot.
+func isStdlib(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
@@ -0,0 +1,250 @@
+package rundecktest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+type jobSummaryList struct {
+	XMLName xml.Name     `xml:"jobs"`
+	Count   int          `xml:"count,attr"`
+	Jobs    []jobSummary `xml:"job"`
+}
+
+type jobSummary struct {
+	ID          string `xml:"id,attr"`
+	Name        string `xml:"name"`
+	GroupName   string `xml:"group"`
+	ProjectName string `xml:"project"`
+	Description string `xml:"description"`
+}
+
+type jobDetailList struct {
+	XMLName xml.Name            `xml:"joblist"`
+	Jobs    []rundeck.JobDetail `xml:"job"`
+}
+
+type jobImportResults struct {
+	XMLName   xml.Name                 `xml:"result"`
+	Succeeded jobImportResultsCategory `xml:"succeeded"`
+	Failed    jobImportResultsCategory `xml:"failed"`
+	Skipped   jobImportResultsCategory `xml:"skipped"`
+}
+
+type jobImportResultsCategory struct {
+	Count   int               `xml:"count,attr"`
+	Results []jobImportResult `xml:"job"`
+}
+
+type jobImportResult struct {
+	Index       int    `xml:"index,attr"`
+	ID          string `xml:"id,omitempty"`
+	Name        string `xml:"name"`
+	GroupName   string `xml:"group,omitempty"`
+	ProjectName string `xml:"context>project,omitempty"`
+	Error       string `xml:"error,omitempty"`
+}
+
+// projectJobs returns the jobs in the named project, sorted by group and name.
+func (s *Server) projectJobs(projectName string) []*rundeck.JobDetail {
+	jobs := []*rundeck.JobDetail{}
+	for _, job := range s.jobs {
+		if job.ProjectName == projectName {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].GroupName != jobs[j].GroupName {
+			return jobs[i].GroupName < jobs[j].GroupName
+		}
+		return jobs[i].Name < jobs[j].Name
+	})
+	return jobs
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request, projectName string) {
+	if _, ok := s.projects[projectName]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", projectName))
+		return
+	}
+
+	result := jobSummaryList{}
+	for _, job := range s.projectJobs(projectName) {
+		result.Jobs = append(result.Jobs, jobSummary{
+			ID:          job.ID,
+			Name:        job.Name,
+			GroupName:   job.GroupName,
+			ProjectName: job.ProjectName,
+			Description: job.Description,
+		})
+	}
+	result.Count = len(result.Jobs)
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) exportJobs(w http.ResponseWriter, r *http.Request) {
+	projectName := r.URL.Query().Get("project")
+	if _, ok := s.projects[projectName]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", projectName))
+		return
+	}
+
+	result := jobDetailList{}
+	for _, job := range s.projectJobs(projectName) {
+		result.Jobs = append(result.Jobs, *job)
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) getJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := s.jobs[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("job %s does not exist", id))
+		return
+	}
+	writeXML(w, http.StatusOK, jobDetailList{
+		Jobs: []rundeck.JobDetail{*job},
+	})
+}
+
+func (s *Server) deleteJob(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := s.jobs[id]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("job %s does not exist", id))
+		return
+	}
+	delete(s.jobs, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importJobs implements the subset of the job import endpoint that the client
+// uses: an XML batch given as a multipart file, with the "dupeOption" and
+// "uuidOption" arguments.
+func (s *Server) importJobs(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid multipart request: %s", err))
+		return
+	}
+	dupeOption := r.FormValue("dupeOption")
+	if dupeOption == "" {
+		dupeOption = "create"
+	}
+	preserveUUID := r.FormValue("uuidOption") != "remove"
+
+	file, _, err := r.FormFile("xmlBatch")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "xmlBatch file is required")
+		return
+	}
+	defer file.Close()
+	batch, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	jobList := jobDetailList{}
+	if err := xml.Unmarshal(batch, &jobList); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid job XML: %s", err))
+		return
+	}
+
+	results := jobImportResults{}
+	for i := range jobList.Jobs {
+		job := jobList.Jobs[i]
+		if !preserveUUID {
+			job.ID = ""
+		}
+		result := jobImportResult{
+			Index:       i + 1,
+			Name:        job.Name,
+			GroupName:   job.GroupName,
+			ProjectName: job.ProjectName,
+		}
+
+		category, errMsg := s.importJob(&job, dupeOption)
+		switch category {
+		case "succeeded":
+			result.ID = job.ID
+			results.Succeeded.Results = append(results.Succeeded.Results, result)
+		case "skipped":
+			results.Skipped.Results = append(results.Skipped.Results, result)
+		default:
+			result.Error = errMsg
+			results.Failed.Results = append(results.Failed.Results, result)
+		}
+	}
+	results.Succeeded.Count = len(results.Succeeded.Results)
+	results.Failed.Count = len(results.Failed.Results)
+	results.Skipped.Count = len(results.Skipped.Results)
+	writeXML(w, http.StatusOK, results)
+}
+
+// importJob stores a single imported job, returning the name of the result
+// category it belongs in and, for failures, an error message.
+func (s *Server) importJob(job *rundeck.JobDetail, dupeOption string) (string, string) {
+	if job.Name == "" {
+		return "failed", "job name is required"
+	}
+	if _, ok := s.projects[job.ProjectName]; !ok {
+		return "failed", fmt.Sprintf("project %q does not exist", job.ProjectName)
+	}
+
+	var existing *rundeck.JobDetail
+	if job.ID != "" {
+		existing = s.jobs[job.ID]
+		if existing != nil && existing.ProjectName != job.ProjectName {
+			return "failed", fmt.Sprintf("job with uuid %s already exists in project %s", job.ID, existing.ProjectName)
+		}
+	}
+	if existing == nil {
+		for _, other := range s.jobs {
+			if other.ProjectName == job.ProjectName && other.GroupName == job.GroupName && other.Name == job.Name {
+				existing = other
+				break
+			}
+		}
+	}
+
+	switch {
+	case existing == nil:
+		if job.ID == "" {
+			job.ID = newUUID()
+		}
+	case dupeOption == "skip":
+		return "skipped", ""
+	case dupeOption == "update":
+		if job.ID != "" && job.ID != existing.ID {
+			delete(s.jobs, existing.ID)
+		} else {
+			job.ID = existing.ID
+		}
+	default:
+		if job.ID == existing.ID {
+			return "failed", fmt.Sprintf("job with uuid %s already exists", job.ID)
+		}
+		if job.ID == "" {
+			job.ID = newUUID()
+		}
+	}
+
+	s.jobs[job.ID] = job
+	return "succeeded", ""
+}
+
+// copyJob returns a deep copy of job, made by encoding it as XML and decoding
+// it again, so that it shares no pointers or slices with the original.
+func copyJob(job *rundeck.JobDetail) (*rundeck.JobDetail, error) {
+	data, err := xml.Marshal(job)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job: %s", err)
+	}
+	ret := &rundeck.JobDetail{}
+	if err := xml.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("invalid job: %s", err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,232 @@
+package rundecktest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+type storedKey struct {
+	ContentType string
+	KeyType     string
+	Content     []byte
+	Created     time.Time
+	Modified    time.Time
+}
+
+type keyResource struct {
+	XMLName  xml.Name     `xml:"resource"`
+	Name     string       `xml:"name,attr,omitempty"`
+	Path     string       `xml:"path,attr"`
+	Type     string       `xml:"type,attr"`
+	URL      string       `xml:"url,attr"`
+	Meta     *keyMeta     `xml:"resource-meta,omitempty"`
+	Contents *keyContents `xml:"contents,omitempty"`
+}
+
+type keyMeta struct {
+	ContentType  string `xml:"Rundeck-content-type"`
+	ContentSize  int    `xml:"Rundeck-content-size"`
+	ContentMask  string `xml:"Rundeck-content-mask,omitempty"`
+	KeyType      string `xml:"Rundeck-key-type,omitempty"`
+	DataType     string `xml:"Rundeck-data-type,omitempty"`
+	CreatedTime  string `xml:"Rundeck-content-creation-time"`
+	ModifiedTime string `xml:"Rundeck-content-modify-time"`
+}
+
+type keyContents struct {
+	Count     int           `xml:"count,attr"`
+	Resources []keyResource `xml:"resource"`
+}
+
+const timestampFormat = "2006-01-02T15:04:05Z"
+
+func newStoredKey(contentType string, content []byte) (*storedKey, error) {
+	key := &storedKey{
+		ContentType: contentType,
+		Content:     append([]byte(nil), content...),
+		Created:     time.Now().UTC(),
+	}
+	key.Modified = key.Created
+
+	switch contentType {
+	case "application/pgp-keys":
+		key.KeyType = "public"
+	case "application/octet-stream":
+		key.KeyType = "private"
+	case "application/x-rundeck-data-password":
+		key.KeyType = "password"
+	default:
+		return nil, fmt.Errorf("unsupported key content type %q", contentType)
+	}
+	return key, nil
+}
+
+// normalizeKeyPath trims the slashes from either end of a key storage path,
+// so that all of the server's maps can use a consistent form.
+func normalizeKeyPath(path string) string {
+	return strings.Trim(path, "/")
+}
+
+func (s *Server) keyURL(path string) string {
+	if path == "" {
+		return fmt.Sprintf("%s/api/13/storage/keys", s.URL)
+	}
+	return fmt.Sprintf("%s/api/13/storage/keys/%s", s.URL, rundeck.KeyPath(path).Escaped())
+}
+
+func (s *Server) keyResource(path string, key *storedKey) keyResource {
+	meta := &keyMeta{
+		ContentType:  key.ContentType,
+		ContentSize:  len(key.Content),
+		CreatedTime:  key.Created.Format(timestampFormat),
+		ModifiedTime: key.Modified.Format(timestampFormat),
+	}
+	switch key.KeyType {
+	case "public":
+		meta.KeyType = "public"
+	case "private":
+		meta.KeyType = "private"
+		meta.ContentMask = "content"
+	case "password":
+		meta.DataType = "password"
+		meta.ContentMask = "content"
+	}
+
+	return keyResource{
+		Name: path[strings.LastIndex(path, "/")+1:],
+		Path: "keys/" + path,
+		Type: "file",
+		URL:  s.keyURL(path),
+		Meta: meta,
+	}
+}
+
+// isKeyDir returns true if there is at least one key beneath the given path.
+// The root of the key storage is always a directory.
+func (s *Server) isKeyDir(path string) bool {
+	if path == "" {
+		return true
+	}
+	for keyPath := range s.keys {
+		if strings.HasPrefix(keyPath, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) getKey(w http.ResponseWriter, r *http.Request, path string) {
+	if key, ok := s.keys[path]; ok {
+		if strings.Contains(r.Header.Get("Accept"), "xml") {
+			writeXML(w, http.StatusOK, s.keyResource(path, key))
+			return
+		}
+		if key.KeyType != "public" {
+			writeError(w, http.StatusForbidden, fmt.Sprintf("key %s is not readable", path))
+			return
+		}
+		w.Header().Set("Content-Type", key.ContentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(key.Content)
+		return
+	}
+
+	if !s.isKeyDir(path) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("key %s does not exist", path))
+		return
+	}
+
+	prefix := ""
+	if path != "" {
+		prefix = path + "/"
+	}
+	children := map[string]keyResource{}
+	for keyPath, key := range s.keys {
+		if !strings.HasPrefix(keyPath, prefix) {
+			continue
+		}
+		rest := keyPath[len(prefix):]
+		if i := strings.Index(rest, "/"); i >= 0 {
+			dirPath := prefix + rest[:i]
+			children[dirPath] = keyResource{
+				Path: "keys/" + dirPath,
+				Type: "directory",
+				URL:  s.keyURL(dirPath),
+			}
+		} else {
+			children[keyPath] = s.keyResource(keyPath, key)
+		}
+	}
+	childPaths := make([]string, 0, len(children))
+	for childPath := range children {
+		childPaths = append(childPaths, childPath)
+	}
+	sort.Strings(childPaths)
+
+	dirPath := "keys"
+	if path != "" {
+		dirPath = "keys/" + path
+	}
+	result := keyResource{
+		Path:     dirPath,
+		Type:     "directory",
+		URL:      s.keyURL(path),
+		Contents: &keyContents{},
+	}
+	for _, childPath := range childPaths {
+		result.Contents.Resources = append(result.Contents.Resources, children[childPath])
+	}
+	result.Contents.Count = len(childPaths)
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) createOrReplaceKey(w http.ResponseWriter, r *http.Request, path string, replace bool) {
+	if path == "" || s.isKeyDir(path) {
+		writeError(w, http.StatusConflict, fmt.Sprintf("%s is a directory", path))
+		return
+	}
+	existing, exists := s.keys[path]
+	if replace && !exists {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("key %s does not exist", path))
+		return
+	}
+	if !replace && exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("key %s already exists", path))
+		return
+	}
+
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	key, err := newStoredKey(r.Header.Get("Content-Type"), content)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := http.StatusCreated
+	if replace {
+		key.Created = existing.Created
+		status = http.StatusOK
+	}
+	s.keys[path] = key
+	writeXML(w, status, s.keyResource(path, key))
+}
+
+func (s *Server) deleteKey(w http.ResponseWriter, r *http.Request, path string) {
+	if _, ok := s.keys[path]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("key %s does not exist", path))
+		return
+	}
+	delete(s.keys, path)
+	w.WriteHeader(http.StatusNoContent)
+}
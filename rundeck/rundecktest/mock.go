@@ -0,0 +1,59 @@
+package rundecktest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+//go:generate go run ./internal/mockgen -source ../services.go -out mock_gen.go
+
+// ErrNotMocked is wrapped by the errors that MockClient methods return when
+// their corresponding function field is not set.
+var ErrNotMocked = errors.New("method not mocked")
+
+// MockCall is a record of a single call to a MockClient method.
+type MockCall struct {
+	Method string
+	Args   []interface{}
+}
+
+// mockRecorder keeps track of the calls made to a MockClient. It is
+// embedded in the generated MockClient type.
+type mockRecorder struct {
+	mu    sync.Mutex
+	calls []MockCall
+}
+
+// Calls returns the calls made so far, in the order they were made.
+func (r *mockRecorder) Calls() []MockCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]MockCall(nil), r.calls...)
+}
+
+// CallsTo returns the calls made so far to the named method.
+func (r *mockRecorder) CallsTo(method string) []MockCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ret := []MockCall{}
+	for _, call := range r.calls {
+		if call.Method == method {
+			ret = append(ret, call)
+		}
+	}
+	return ret
+}
+
+func (r *mockRecorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, MockCall{
+		Method: method,
+		Args:   args,
+	})
+}
+
+func notMocked(method string) error {
+	return fmt.Errorf("rundecktest: %s: %w", method, ErrNotMocked)
+}
@@ -0,0 +1,556 @@
+// Code generated by mockgen from ../services.go; DO NOT EDIT.
+
+package rundecktest
+
+import (
+	"io"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+// MockClient is an implementation of rundeck.API for use in tests.
+//
+// Each method records its call and then calls the corresponding function
+// field, such as GetJobFunc for GetJob. If the function field is nil, the
+// method returns zero values and an error wrapping ErrNotMocked.
+type MockClient struct {
+	GetJobSummariesForProjectFunc func(projectName string) ([]rundeck.JobSummary, error)
+	GetJobsForProjectFunc         func(projectName string) ([]rundeck.JobDetail, error)
+	GetJobFunc                    func(id string) (*rundeck.JobDetail, error)
+	CreateJobFunc                 func(job *rundeck.JobDetail) (*rundeck.JobSummary, error)
+	CreateOrUpdateJobFunc         func(job *rundeck.JobDetail) (*rundeck.JobSummary, error)
+	DeleteJobFunc                 func(id string) error
+	GetAllProjectsFunc            func() ([]rundeck.ProjectSummary, error)
+	GetProjectFunc                func(name string) (*rundeck.Project, error)
+	CreateProjectFunc             func(project *rundeck.Project) (*rundeck.Project, error)
+	DeleteProjectFunc             func(name string) error
+	SetProjectConfigFunc          func(projectName string, config rundeck.ProjectConfig) error
+	GetProjectConfigFunc          func(projectName string) (rundeck.ProjectConfig, error)
+	GetProjectConfigKeyFunc       func(projectName string, key string) (string, error)
+	SetProjectConfigKeyFunc       func(projectName string, key string, value string) error
+	DeleteProjectConfigKeyFunc    func(projectName string, key string) error
+	MergeProjectConfigFunc        func(projectName string, set rundeck.ProjectConfig, remove []string) error
+	GetProjectReadmeFunc          func(projectName string) (string, error)
+	SetProjectReadmeFunc          func(projectName string, content string) error
+	DeleteProjectReadmeFunc       func(projectName string) error
+	GetProjectMOTDFunc            func(projectName string) (string, error)
+	SetProjectMOTDFunc            func(projectName string, content string) error
+	DeleteProjectMOTDFunc         func(projectName string) error
+	GetProjectSettingsFunc        func(projectName string) (*rundeck.ProjectSettings, error)
+	UpdateProjectSettingsFunc     func(projectName string, settings *rundeck.ProjectSettings) error
+	ListResourceSourcesFunc       func(projectName string) ([]rundeck.ResourceSource, error)
+	SetResourceSourcesFunc        func(projectName string, sources []rundeck.ResourceSource) error
+	AddResourceSourceFunc         func(projectName string, source rundeck.ResourceSource) (int, error)
+	RemoveResourceSourceFunc      func(projectName string, number int) error
+	ReorderResourceSourcesFunc    func(projectName string, order []int) error
+	GetKeyMetaFunc                func(path string) (*rundeck.KeyMeta, error)
+	GetKeysInDirMetaFunc          func(path string) ([]rundeck.KeyMeta, error)
+	GetKeyContentFunc             func(path string) (string, error)
+	CreatePublicKeyFunc           func(path string, content string) error
+	ReplacePublicKeyFunc          func(path string, content string) error
+	CreatePrivateKeyFunc          func(path string, content string) error
+	ReplacePrivateKeyFunc         func(path string, content string) error
+	CreatePasswordFunc            func(path string, content string) error
+	ReplacePasswordFunc           func(path string, content string) error
+	DeleteKeyFunc                 func(path string) error
+	GetKeyContentBytesFunc        func(path string) ([]byte, error)
+	CopyKeyContentFunc            func(path string, w io.Writer) (int64, error)
+	CreateKeyFunc                 func(path string, kind rundeck.KeyKind, content io.Reader) error
+	ReplaceKeyFunc                func(path string, kind rundeck.KeyKind, content io.Reader) error
+	CreateSSHKeyPairFunc          func(path string, opts rundeck.SSHKeyOptions) (string, error)
+	WalkKeysFunc                  func(root string, fn rundeck.KeyWalkFunc) error
+	WalkKeysParallelFunc          func(root string, parallelism int, fn rundeck.KeyWalkFunc) error
+	GetKeyManifestFunc            func(root string) (*rundeck.KeyManifest, error)
+	PlanKeySyncFunc               func(root string, desired map[string]rundeck.KeySpec, opts rundeck.KeySyncOptions) (*rundeck.KeySyncPlan, error)
+	ApplyKeySyncFunc              func(plan *rundeck.KeySyncPlan) error
+	SyncKeysFunc                  func(root string, desired map[string]rundeck.KeySpec, opts rundeck.KeySyncOptions) (*rundeck.KeySyncPlan, error)
+	GetSystemInfoFunc             func() (*rundeck.SystemInfo, error)
+
+	mockRecorder
+}
+
+var _ rundeck.API = (*MockClient)(nil)
+
+// GetJobSummariesForProject calls GetJobSummariesForProjectFunc.
+func (m *MockClient) GetJobSummariesForProject(projectName string) ([]rundeck.JobSummary, error) {
+	m.record("GetJobSummariesForProject", projectName)
+	if m.GetJobSummariesForProjectFunc == nil {
+		var r0 []rundeck.JobSummary
+		return r0, notMocked("GetJobSummariesForProject")
+	}
+	return m.GetJobSummariesForProjectFunc(projectName)
+}
+
+// GetJobsForProject calls GetJobsForProjectFunc.
+func (m *MockClient) GetJobsForProject(projectName string) ([]rundeck.JobDetail, error) {
+	m.record("GetJobsForProject", projectName)
+	if m.GetJobsForProjectFunc == nil {
+		var r0 []rundeck.JobDetail
+		return r0, notMocked("GetJobsForProject")
+	}
+	return m.GetJobsForProjectFunc(projectName)
+}
+
+// GetJob calls GetJobFunc.
+func (m *MockClient) GetJob(id string) (*rundeck.JobDetail, error) {
+	m.record("GetJob", id)
+	if m.GetJobFunc == nil {
+		var r0 *rundeck.JobDetail
+		return r0, notMocked("GetJob")
+	}
+	return m.GetJobFunc(id)
+}
+
+// CreateJob calls CreateJobFunc.
+func (m *MockClient) CreateJob(job *rundeck.JobDetail) (*rundeck.JobSummary, error) {
+	m.record("CreateJob", job)
+	if m.CreateJobFunc == nil {
+		var r0 *rundeck.JobSummary
+		return r0, notMocked("CreateJob")
+	}
+	return m.CreateJobFunc(job)
+}
+
+// CreateOrUpdateJob calls CreateOrUpdateJobFunc.
+func (m *MockClient) CreateOrUpdateJob(job *rundeck.JobDetail) (*rundeck.JobSummary, error) {
+	m.record("CreateOrUpdateJob", job)
+	if m.CreateOrUpdateJobFunc == nil {
+		var r0 *rundeck.JobSummary
+		return r0, notMocked("CreateOrUpdateJob")
+	}
+	return m.CreateOrUpdateJobFunc(job)
+}
+
+// DeleteJob calls DeleteJobFunc.
+func (m *MockClient) DeleteJob(id string) error {
+	m.record("DeleteJob", id)
+	if m.DeleteJobFunc == nil {
+		return notMocked("DeleteJob")
+	}
+	return m.DeleteJobFunc(id)
+}
+
+// GetAllProjects calls GetAllProjectsFunc.
+func (m *MockClient) GetAllProjects() ([]rundeck.ProjectSummary, error) {
+	m.record("GetAllProjects")
+	if m.GetAllProjectsFunc == nil {
+		var r0 []rundeck.ProjectSummary
+		return r0, notMocked("GetAllProjects")
+	}
+	return m.GetAllProjectsFunc()
+}
+
+// GetProject calls GetProjectFunc.
+func (m *MockClient) GetProject(name string) (*rundeck.Project, error) {
+	m.record("GetProject", name)
+	if m.GetProjectFunc == nil {
+		var r0 *rundeck.Project
+		return r0, notMocked("GetProject")
+	}
+	return m.GetProjectFunc(name)
+}
+
+// CreateProject calls CreateProjectFunc.
+func (m *MockClient) CreateProject(project *rundeck.Project) (*rundeck.Project, error) {
+	m.record("CreateProject", project)
+	if m.CreateProjectFunc == nil {
+		var r0 *rundeck.Project
+		return r0, notMocked("CreateProject")
+	}
+	return m.CreateProjectFunc(project)
+}
+
+// DeleteProject calls DeleteProjectFunc.
+func (m *MockClient) DeleteProject(name string) error {
+	m.record("DeleteProject", name)
+	if m.DeleteProjectFunc == nil {
+		return notMocked("DeleteProject")
+	}
+	return m.DeleteProjectFunc(name)
+}
+
+// SetProjectConfig calls SetProjectConfigFunc.
+func (m *MockClient) SetProjectConfig(projectName string, config rundeck.ProjectConfig) error {
+	m.record("SetProjectConfig", projectName, config)
+	if m.SetProjectConfigFunc == nil {
+		return notMocked("SetProjectConfig")
+	}
+	return m.SetProjectConfigFunc(projectName, config)
+}
+
+// GetProjectConfig calls GetProjectConfigFunc.
+func (m *MockClient) GetProjectConfig(projectName string) (rundeck.ProjectConfig, error) {
+	m.record("GetProjectConfig", projectName)
+	if m.GetProjectConfigFunc == nil {
+		var r0 rundeck.ProjectConfig
+		return r0, notMocked("GetProjectConfig")
+	}
+	return m.GetProjectConfigFunc(projectName)
+}
+
+// GetProjectConfigKey calls GetProjectConfigKeyFunc.
+func (m *MockClient) GetProjectConfigKey(projectName string, key string) (string, error) {
+	m.record("GetProjectConfigKey", projectName, key)
+	if m.GetProjectConfigKeyFunc == nil {
+		var r0 string
+		return r0, notMocked("GetProjectConfigKey")
+	}
+	return m.GetProjectConfigKeyFunc(projectName, key)
+}
+
+// SetProjectConfigKey calls SetProjectConfigKeyFunc.
+func (m *MockClient) SetProjectConfigKey(projectName string, key string, value string) error {
+	m.record("SetProjectConfigKey", projectName, key, value)
+	if m.SetProjectConfigKeyFunc == nil {
+		return notMocked("SetProjectConfigKey")
+	}
+	return m.SetProjectConfigKeyFunc(projectName, key, value)
+}
+
+// DeleteProjectConfigKey calls DeleteProjectConfigKeyFunc.
+func (m *MockClient) DeleteProjectConfigKey(projectName string, key string) error {
+	m.record("DeleteProjectConfigKey", projectName, key)
+	if m.DeleteProjectConfigKeyFunc == nil {
+		return notMocked("DeleteProjectConfigKey")
+	}
+	return m.DeleteProjectConfigKeyFunc(projectName, key)
+}
+
+// MergeProjectConfig calls MergeProjectConfigFunc.
+func (m *MockClient) MergeProjectConfig(projectName string, set rundeck.ProjectConfig, remove []string) error {
+	m.record("MergeProjectConfig", projectName, set, remove)
+	if m.MergeProjectConfigFunc == nil {
+		return notMocked("MergeProjectConfig")
+	}
+	return m.MergeProjectConfigFunc(projectName, set, remove)
+}
+
+// GetProjectReadme calls GetProjectReadmeFunc.
+func (m *MockClient) GetProjectReadme(projectName string) (string, error) {
+	m.record("GetProjectReadme", projectName)
+	if m.GetProjectReadmeFunc == nil {
+		var r0 string
+		return r0, notMocked("GetProjectReadme")
+	}
+	return m.GetProjectReadmeFunc(projectName)
+}
+
+// SetProjectReadme calls SetProjectReadmeFunc.
+func (m *MockClient) SetProjectReadme(projectName string, content string) error {
+	m.record("SetProjectReadme", projectName, content)
+	if m.SetProjectReadmeFunc == nil {
+		return notMocked("SetProjectReadme")
+	}
+	return m.SetProjectReadmeFunc(projectName, content)
+}
+
+// DeleteProjectReadme calls DeleteProjectReadmeFunc.
+func (m *MockClient) DeleteProjectReadme(projectName string) error {
+	m.record("DeleteProjectReadme", projectName)
+	if m.DeleteProjectReadmeFunc == nil {
+		return notMocked("DeleteProjectReadme")
+	}
+	return m.DeleteProjectReadmeFunc(projectName)
+}
+
+// GetProjectMOTD calls GetProjectMOTDFunc.
+func (m *MockClient) GetProjectMOTD(projectName string) (string, error) {
+	m.record("GetProjectMOTD", projectName)
+	if m.GetProjectMOTDFunc == nil {
+		var r0 string
+		return r0, notMocked("GetProjectMOTD")
+	}
+	return m.GetProjectMOTDFunc(projectName)
+}
+
+// SetProjectMOTD calls SetProjectMOTDFunc.
+func (m *MockClient) SetProjectMOTD(projectName string, content string) error {
+	m.record("SetProjectMOTD", projectName, content)
+	if m.SetProjectMOTDFunc == nil {
+		return notMocked("SetProjectMOTD")
+	}
+	return m.SetProjectMOTDFunc(projectName, content)
+}
+
+// DeleteProjectMOTD calls DeleteProjectMOTDFunc.
+func (m *MockClient) DeleteProjectMOTD(projectName string) error {
+	m.record("DeleteProjectMOTD", projectName)
+	if m.DeleteProjectMOTDFunc == nil {
+		return notMocked("DeleteProjectMOTD")
+	}
+	return m.DeleteProjectMOTDFunc(projectName)
+}
+
+// GetProjectSettings calls GetProjectSettingsFunc.
+func (m *MockClient) GetProjectSettings(projectName string) (*rundeck.ProjectSettings, error) {
+	m.record("GetProjectSettings", projectName)
+	if m.GetProjectSettingsFunc == nil {
+		var r0 *rundeck.ProjectSettings
+		return r0, notMocked("GetProjectSettings")
+	}
+	return m.GetProjectSettingsFunc(projectName)
+}
+
+// UpdateProjectSettings calls UpdateProjectSettingsFunc.
+func (m *MockClient) UpdateProjectSettings(projectName string, settings *rundeck.ProjectSettings) error {
+	m.record("UpdateProjectSettings", projectName, settings)
+	if m.UpdateProjectSettingsFunc == nil {
+		return notMocked("UpdateProjectSettings")
+	}
+	return m.UpdateProjectSettingsFunc(projectName, settings)
+}
+
+// ListResourceSources calls ListResourceSourcesFunc.
+func (m *MockClient) ListResourceSources(projectName string) ([]rundeck.ResourceSource, error) {
+	m.record("ListResourceSources", projectName)
+	if m.ListResourceSourcesFunc == nil {
+		var r0 []rundeck.ResourceSource
+		return r0, notMocked("ListResourceSources")
+	}
+	return m.ListResourceSourcesFunc(projectName)
+}
+
+// SetResourceSources calls SetResourceSourcesFunc.
+func (m *MockClient) SetResourceSources(projectName string, sources []rundeck.ResourceSource) error {
+	m.record("SetResourceSources", projectName, sources)
+	if m.SetResourceSourcesFunc == nil {
+		return notMocked("SetResourceSources")
+	}
+	return m.SetResourceSourcesFunc(projectName, sources)
+}
+
+// AddResourceSource calls AddResourceSourceFunc.
+func (m *MockClient) AddResourceSource(projectName string, source rundeck.ResourceSource) (int, error) {
+	m.record("AddResourceSource", projectName, source)
+	if m.AddResourceSourceFunc == nil {
+		var r0 int
+		return r0, notMocked("AddResourceSource")
+	}
+	return m.AddResourceSourceFunc(projectName, source)
+}
+
+// RemoveResourceSource calls RemoveResourceSourceFunc.
+func (m *MockClient) RemoveResourceSource(projectName string, number int) error {
+	m.record("RemoveResourceSource", projectName, number)
+	if m.RemoveResourceSourceFunc == nil {
+		return notMocked("RemoveResourceSource")
+	}
+	return m.RemoveResourceSourceFunc(projectName, number)
+}
+
+// ReorderResourceSources calls ReorderResourceSourcesFunc.
+func (m *MockClient) ReorderResourceSources(projectName string, order []int) error {
+	m.record("ReorderResourceSources", projectName, order)
+	if m.ReorderResourceSourcesFunc == nil {
+		return notMocked("ReorderResourceSources")
+	}
+	return m.ReorderResourceSourcesFunc(projectName, order)
+}
+
+// GetKeyMeta calls GetKeyMetaFunc.
+func (m *MockClient) GetKeyMeta(path string) (*rundeck.KeyMeta, error) {
+	m.record("GetKeyMeta", path)
+	if m.GetKeyMetaFunc == nil {
+		var r0 *rundeck.KeyMeta
+		return r0, notMocked("GetKeyMeta")
+	}
+	return m.GetKeyMetaFunc(path)
+}
+
+// GetKeysInDirMeta calls GetKeysInDirMetaFunc.
+func (m *MockClient) GetKeysInDirMeta(path string) ([]rundeck.KeyMeta, error) {
+	m.record("GetKeysInDirMeta", path)
+	if m.GetKeysInDirMetaFunc == nil {
+		var r0 []rundeck.KeyMeta
+		return r0, notMocked("GetKeysInDirMeta")
+	}
+	return m.GetKeysInDirMetaFunc(path)
+}
+
+// GetKeyContent calls GetKeyContentFunc.
+func (m *MockClient) GetKeyContent(path string) (string, error) {
+	m.record("GetKeyContent", path)
+	if m.GetKeyContentFunc == nil {
+		var r0 string
+		return r0, notMocked("GetKeyContent")
+	}
+	return m.GetKeyContentFunc(path)
+}
+
+// CreatePublicKey calls CreatePublicKeyFunc.
+func (m *MockClient) CreatePublicKey(path string, content string) error {
+	m.record("CreatePublicKey", path, content)
+	if m.CreatePublicKeyFunc == nil {
+		return notMocked("CreatePublicKey")
+	}
+	return m.CreatePublicKeyFunc(path, content)
+}
+
+// ReplacePublicKey calls ReplacePublicKeyFunc.
+func (m *MockClient) ReplacePublicKey(path string, content string) error {
+	m.record("ReplacePublicKey", path, content)
+	if m.ReplacePublicKeyFunc == nil {
+		return notMocked("ReplacePublicKey")
+	}
+	return m.ReplacePublicKeyFunc(path, content)
+}
+
+// CreatePrivateKey calls CreatePrivateKeyFunc.
+func (m *MockClient) CreatePrivateKey(path string, content string) error {
+	m.record("CreatePrivateKey", path, content)
+	if m.CreatePrivateKeyFunc == nil {
+		return notMocked("CreatePrivateKey")
+	}
+	return m.CreatePrivateKeyFunc(path, content)
+}
+
+// ReplacePrivateKey calls ReplacePrivateKeyFunc.
+func (m *MockClient) ReplacePrivateKey(path string, content string) error {
+	m.record("ReplacePrivateKey", path, content)
+	if m.ReplacePrivateKeyFunc == nil {
+		return notMocked("ReplacePrivateKey")
+	}
+	return m.ReplacePrivateKeyFunc(path, content)
+}
+
+// CreatePassword calls CreatePasswordFunc.
+func (m *MockClient) CreatePassword(path string, content string) error {
+	m.record("CreatePassword", path, content)
+	if m.CreatePasswordFunc == nil {
+		return notMocked("CreatePassword")
+	}
+	return m.CreatePasswordFunc(path, content)
+}
+
+// ReplacePassword calls ReplacePasswordFunc.
+func (m *MockClient) ReplacePassword(path string, content string) error {
+	m.record("ReplacePassword", path, content)
+	if m.ReplacePasswordFunc == nil {
+		return notMocked("ReplacePassword")
+	}
+	return m.ReplacePasswordFunc(path, content)
+}
+
+// DeleteKey calls DeleteKeyFunc.
+func (m *MockClient) DeleteKey(path string) error {
+	m.record("DeleteKey", path)
+	if m.DeleteKeyFunc == nil {
+		return notMocked("DeleteKey")
+	}
+	return m.DeleteKeyFunc(path)
+}
+
+// GetKeyContentBytes calls GetKeyContentBytesFunc.
+func (m *MockClient) GetKeyContentBytes(path string) ([]byte, error) {
+	m.record("GetKeyContentBytes", path)
+	if m.GetKeyContentBytesFunc == nil {
+		var r0 []byte
+		return r0, notMocked("GetKeyContentBytes")
+	}
+	return m.GetKeyContentBytesFunc(path)
+}
+
+// CopyKeyContent calls CopyKeyContentFunc.
+func (m *MockClient) CopyKeyContent(path string, w io.Writer) (int64, error) {
+	m.record("CopyKeyContent", path, w)
+	if m.CopyKeyContentFunc == nil {
+		var r0 int64
+		return r0, notMocked("CopyKeyContent")
+	}
+	return m.CopyKeyContentFunc(path, w)
+}
+
+// CreateKey calls CreateKeyFunc.
+func (m *MockClient) CreateKey(path string, kind rundeck.KeyKind, content io.Reader) error {
+	m.record("CreateKey", path, kind, content)
+	if m.CreateKeyFunc == nil {
+		return notMocked("CreateKey")
+	}
+	return m.CreateKeyFunc(path, kind, content)
+}
+
+// ReplaceKey calls ReplaceKeyFunc.
+func (m *MockClient) ReplaceKey(path string, kind rundeck.KeyKind, content io.Reader) error {
+	m.record("ReplaceKey", path, kind, content)
+	if m.ReplaceKeyFunc == nil {
+		return notMocked("ReplaceKey")
+	}
+	return m.ReplaceKeyFunc(path, kind, content)
+}
+
+// CreateSSHKeyPair calls CreateSSHKeyPairFunc.
+func (m *MockClient) CreateSSHKeyPair(path string, opts rundeck.SSHKeyOptions) (string, error) {
+	m.record("CreateSSHKeyPair", path, opts)
+	if m.CreateSSHKeyPairFunc == nil {
+		var r0 string
+		return r0, notMocked("CreateSSHKeyPair")
+	}
+	return m.CreateSSHKeyPairFunc(path, opts)
+}
+
+// WalkKeys calls WalkKeysFunc.
+func (m *MockClient) WalkKeys(root string, fn rundeck.KeyWalkFunc) error {
+	m.record("WalkKeys", root, fn)
+	if m.WalkKeysFunc == nil {
+		return notMocked("WalkKeys")
+	}
+	return m.WalkKeysFunc(root, fn)
+}
+
+// WalkKeysParallel calls WalkKeysParallelFunc.
+func (m *MockClient) WalkKeysParallel(root string, parallelism int, fn rundeck.KeyWalkFunc) error {
+	m.record("WalkKeysParallel", root, parallelism, fn)
+	if m.WalkKeysParallelFunc == nil {
+		return notMocked("WalkKeysParallel")
+	}
+	return m.WalkKeysParallelFunc(root, parallelism, fn)
+}
+
+// GetKeyManifest calls GetKeyManifestFunc.
+func (m *MockClient) GetKeyManifest(root string) (*rundeck.KeyManifest, error) {
+	m.record("GetKeyManifest", root)
+	if m.GetKeyManifestFunc == nil {
+		var r0 *rundeck.KeyManifest
+		return r0, notMocked("GetKeyManifest")
+	}
+	return m.GetKeyManifestFunc(root)
+}
+
+// PlanKeySync calls PlanKeySyncFunc.
+func (m *MockClient) PlanKeySync(root string, desired map[string]rundeck.KeySpec, opts rundeck.KeySyncOptions) (*rundeck.KeySyncPlan, error) {
+	m.record("PlanKeySync", root, desired, opts)
+	if m.PlanKeySyncFunc == nil {
+		var r0 *rundeck.KeySyncPlan
+		return r0, notMocked("PlanKeySync")
+	}
+	return m.PlanKeySyncFunc(root, desired, opts)
+}
+
+// ApplyKeySync calls ApplyKeySyncFunc.
+func (m *MockClient) ApplyKeySync(plan *rundeck.KeySyncPlan) error {
+	m.record("ApplyKeySync", plan)
+	if m.ApplyKeySyncFunc == nil {
+		return notMocked("ApplyKeySync")
+	}
+	return m.ApplyKeySyncFunc(plan)
+}
+
+// SyncKeys calls SyncKeysFunc.
+func (m *MockClient) SyncKeys(root string, desired map[string]rundeck.KeySpec, opts rundeck.KeySyncOptions) (*rundeck.KeySyncPlan, error) {
+	m.record("SyncKeys", root, desired, opts)
+	if m.SyncKeysFunc == nil {
+		var r0 *rundeck.KeySyncPlan
+		return r0, notMocked("SyncKeys")
+	}
+	return m.SyncKeysFunc(root, desired, opts)
+}
+
+// GetSystemInfo calls GetSystemInfoFunc.
+func (m *MockClient) GetSystemInfo() (*rundeck.SystemInfo, error) {
+	m.record("GetSystemInfo")
+	if m.GetSystemInfoFunc == nil {
+		var r0 *rundeck.SystemInfo
+		return r0, notMocked("GetSystemInfo")
+	}
+	return m.GetSystemInfoFunc()
+}
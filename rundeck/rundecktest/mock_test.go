@@ -0,0 +1,39 @@
+package rundecktest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+func TestMockClient(t *testing.T) {
+	mock := &MockClient{
+		GetJobFunc: func(id string) (*rundeck.JobDetail, error) {
+			return &rundeck.JobDetail{ID: id, Name: "mocked"}, nil
+		},
+	}
+
+	// The mock is used through the interface, as code under test would.
+	var jobs rundeck.JobService = mock
+	job, err := jobs.GetJob("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if job.ID != "abc" || job.Name != "mocked" {
+		t.Errorf("got job %#v", job)
+	}
+
+	err = jobs.DeleteJob("abc")
+	if !errors.Is(err, ErrNotMocked) {
+		t.Errorf("got error %#v from unmocked method, but wanted ErrNotMocked", err)
+	}
+
+	calls := mock.Calls()
+	if len(calls) != 2 || calls[0].Method != "GetJob" || calls[1].Method != "DeleteJob" {
+		t.Fatalf("got calls %#v", calls)
+	}
+	if got := mock.CallsTo("GetJob"); len(got) != 1 || got[0].Args[0] != "abc" {
+		t.Errorf("got GetJob calls %#v", got)
+	}
+}
@@ -0,0 +1,230 @@
+package rundecktest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+type projectList struct {
+	XMLName  xml.Name                 `xml:"projects"`
+	Count    int                      `xml:"count,attr"`
+	Projects []rundeck.ProjectSummary `xml:"project"`
+}
+
+type projectConfigResult struct {
+	XMLName    xml.Name                `xml:"config"`
+	Properties []projectConfigProperty `xml:"property"`
+}
+
+type projectConfigProperty struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func (s *Server) putProject(project rundeck.Project) {
+	project.Config = copyConfig(project.Config)
+	project.URL = fmt.Sprintf("%s/api/13/project/%s", s.URL, project.Name)
+	s.projects[project.Name] = &project
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(s.projects))
+	for name := range s.projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := projectList{
+		Count: len(names),
+	}
+	for _, name := range names {
+		p := s.projects[name]
+		result.Projects = append(result.Projects, rundeck.ProjectSummary{
+			Name:        p.Name,
+			Description: p.Description,
+			URL:         p.URL,
+		})
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	project := rundeck.Project{}
+	if err := readXMLBody(r, &project); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid project XML: %s", err))
+		return
+	}
+	if project.Name == "" {
+		writeError(w, http.StatusBadRequest, "project name is required")
+		return
+	}
+	if _, exists := s.projects[project.Name]; exists {
+		writeError(w, http.StatusConflict, fmt.Sprintf("project %s already exists", project.Name))
+		return
+	}
+
+	s.putProject(project)
+	writeXML(w, http.StatusCreated, s.projects[project.Name])
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request, name string) {
+	project, ok := s.projects[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	writeXML(w, http.StatusOK, project)
+}
+
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request, name string) {
+	if _, ok := s.projects[name]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+
+	delete(s.projects, name)
+	delete(s.projectFiles, name)
+	for id, job := range s.jobs {
+		if job.ProjectName == name {
+			delete(s.jobs, id)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) setProjectConfig(w http.ResponseWriter, r *http.Request, name string) {
+	project, ok := s.projects[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+
+	config := rundeck.ProjectConfig{}
+	if err := readXMLBody(r, &config); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid config XML: %s", err))
+		return
+	}
+
+	project.Config = config
+	writeProjectConfig(w, config)
+}
+
+func (s *Server) getProjectConfig(w http.ResponseWriter, r *http.Request, name string) {
+	project, ok := s.projects[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	writeProjectConfig(w, project.Config)
+}
+
+func writeProjectConfig(w http.ResponseWriter, config rundeck.ProjectConfig) {
+	result := projectConfigResult{}
+	for _, k := range sortedKeys(config) {
+		result.Properties = append(result.Properties, projectConfigProperty{
+			Key:   k,
+			Value: config[k],
+		})
+	}
+	writeXML(w, http.StatusOK, result)
+}
+
+func (s *Server) getProjectConfigKey(w http.ResponseWriter, r *http.Request, name string, key string) {
+	project, ok := s.projects[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	value, ok := project.Config[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s has no config key %s", name, key))
+		return
+	}
+	writeText(w, http.StatusOK, value)
+}
+
+func (s *Server) setProjectConfigKey(w http.ResponseWriter, r *http.Request, name string, key string) {
+	project, ok := s.projects[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	value, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if project.Config == nil {
+		project.Config = rundeck.ProjectConfig{}
+	}
+	project.Config[key] = string(value)
+	writeText(w, http.StatusOK, string(value))
+}
+
+func (s *Server) deleteProjectConfigKey(w http.ResponseWriter, r *http.Request, name string, key string) {
+	project, ok := s.projects[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	delete(project.Config, key)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getProjectFile(w http.ResponseWriter, r *http.Request, name string, file string) {
+	if _, ok := s.projects[name]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	content, ok := s.projectFiles[name][file]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s has no %s", name, file))
+		return
+	}
+	writeText(w, http.StatusOK, content)
+}
+
+func (s *Server) putProjectFile(w http.ResponseWriter, r *http.Request, name string, file string) {
+	if _, ok := s.projects[name]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s does not exist", name))
+		return
+	}
+	content, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.projectFiles[name] == nil {
+		s.projectFiles[name] = map[string]string{}
+	}
+	s.projectFiles[name][file] = string(content)
+	writeText(w, http.StatusOK, string(content))
+}
+
+func (s *Server) deleteProjectFile(w http.ResponseWriter, r *http.Request, name string, file string) {
+	if _, ok := s.projectFiles[name][file]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("project %s has no %s", name, file))
+		return
+	}
+	delete(s.projectFiles[name], file)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeText(w http.ResponseWriter, status int, content string) {
+	w.Header().Set("Content-Type", "text/plain;charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write([]byte(content))
+}
+
+func copyConfig(config rundeck.ProjectConfig) rundeck.ProjectConfig {
+	ret := rundeck.ProjectConfig{}
+	for k, v := range config {
+		ret[k] = v
+	}
+	return ret
+}
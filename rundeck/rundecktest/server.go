@@ -0,0 +1,445 @@
+// Package rundecktest provides an in-process fake Rundeck server for use in
+// tests of code that uses the rundeck package.
+//
+// The fake implements enough of the Rundeck HTTP API to support the methods
+// of rundeck.Client that deal with projects, jobs, key storage and system
+// information, keeping all of its state in memory. It is not a faithful
+// reimplementation of Rundeck: it does not run jobs, and it performs only the
+// validation needed to keep its own state consistent.
+//
+// A typical test creates a server, seeds it with any required state, and
+// then exercises the code under test with the client returned by NewClient:
+//
+//	server := rundecktest.NewServer()
+//	defer server.Close()
+//	server.AddProject(rundeck.Project{Name: "example"})
+//
+//	client, err := server.NewClient()
+package rundecktest
+
+import (
+	"crypto/rand"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+// DefaultAuthToken is the auth token that a new Server expects clients to
+// present.
+const DefaultAuthToken = "rundecktest-token"
+
+// apiVersion is the API version the fake server reports in error responses.
+const apiVersion = "13"
+
+// Server is a fake Rundeck server listening on a local loopback address.
+type Server struct {
+	// URL is the base URL of the server, suitable for use as
+	// rundeck.ClientConfig.BaseURL.
+	URL string
+
+	// AuthToken is the token that clients must send in the
+	// X-Rundeck-Auth-Token header. It may be changed before the server is
+	// used, but not while requests are being handled.
+	AuthToken string
+
+	httpServer *httptest.Server
+
+	mu           sync.Mutex
+	projects     map[string]*rundeck.Project
+	projectFiles map[string]map[string]string
+	jobs         map[string]*rundeck.JobDetail
+	keys         map[string]*storedKey
+	systemInfo   rundeck.SystemInfo
+}
+
+// NewServer starts and returns a new fake server with no projects, jobs or
+// keys. The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		AuthToken:    DefaultAuthToken,
+		projects:     map[string]*rundeck.Project{},
+		projectFiles: map[string]map[string]string{},
+		jobs:         map[string]*rundeck.JobDetail{},
+		keys:         map[string]*storedKey{},
+		systemInfo: rundeck.SystemInfo{
+			Rundeck: rundeck.About{
+				Version:    "2.6.0",
+				APIVersion: 13,
+				Build:      "rundecktest",
+				Node:       "localhost",
+				BaseDir:    "/var/lib/rundeck",
+			},
+		},
+	}
+	s.httpServer = httptest.NewServer(s)
+	s.URL = s.httpServer.URL
+	return s
+}
+
+// Close shuts down the server and blocks until all outstanding requests on
+// it have completed.
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// ClientConfig returns a configuration that will produce a client for this
+// server when passed to rundeck.NewClient.
+func (s *Server) ClientConfig() *rundeck.ClientConfig {
+	return &rundeck.ClientConfig{
+		BaseURL:   s.URL,
+		AuthToken: s.AuthToken,
+	}
+}
+
+// NewClient returns a rundeck.Client that talks to this server.
+func (s *Server) NewClient() (*rundeck.Client, error) {
+	return rundeck.NewClient(s.ClientConfig())
+}
+
+// AddProject creates a project directly in the server's state, replacing
+// any existing project of the same name.
+func (s *Server) AddProject(project rundeck.Project) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.putProject(project)
+}
+
+// Project returns a copy of the named project, or nil if there is no such
+// project.
+func (s *Server) Project(name string) *rundeck.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.projects[name]
+	if !ok {
+		return nil
+	}
+	ret := *p
+	ret.Config = copyConfig(p.Config)
+	return &ret
+}
+
+// AddJob creates or replaces a job directly in the server's state, assigning
+// it an id if it doesn't already have one. The job's project must already
+// exist. The server keeps its own copy of the job, so later changes to job
+// don't affect it.
+func (s *Server) AddJob(job rundeck.JobDetail) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.projects[job.ProjectName]; !ok {
+		return "", fmt.Errorf("project %q does not exist", job.ProjectName)
+	}
+	if job.ID == "" {
+		job.ID = newUUID()
+	}
+	stored, err := copyJob(&job)
+	if err != nil {
+		return "", err
+	}
+	s.jobs[job.ID] = stored
+	return job.ID, nil
+}
+
+// Job returns a copy of the job with the given id, or nil if there is no
+// such job.
+func (s *Server) Job(id string) *rundeck.JobDetail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil
+	}
+	ret, err := copyJob(job)
+	if err != nil {
+		// Every stored job has already been copied in the same way.
+		panic(err)
+	}
+	return ret
+}
+
+// AddKey creates or replaces a key directly in the server's key storage.
+// The contentType must be one of the types accepted by the key storage API:
+// "application/pgp-keys", "application/octet-stream" or
+// "application/x-rundeck-data-password".
+func (s *Server) AddKey(path string, contentType string, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, err := newStoredKey(contentType, content)
+	if err != nil {
+		return err
+	}
+	s.keys[normalizeKeyPath(path)] = key
+	return nil
+}
+
+// KeyContent returns the content of the key at the given path, including
+// the content of private keys and passwords that cannot be read through the
+// API. The second result is false if there is no such key.
+func (s *Server) KeyContent(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[normalizeKeyPath(path)]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), key.Content...), true
+}
+
+// SetSystemInfo replaces the information returned by the system info
+// endpoint. The server timestamp is always set to the current time when
+// the information is requested.
+func (s *Server) SetSystemInfo(info rundeck.SystemInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.systemInfo = info
+}
+
+// ServeHTTP implements http.Handler, dispatching API requests to the
+// appropriate fake endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Rundeck-Auth-Token") != s.AuthToken {
+		writeError(w, http.StatusForbidden, "unauthorized")
+		return
+	}
+
+	// Paths are of the form /api/<version>/<endpoint...>; we accept any
+	// version since the fake doesn't vary its behavior. We split the
+	// escaped path so that escaped slashes remain within their segments.
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		writeError(w, http.StatusNotFound, "not an API path")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/"), "/")
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid path: %s", err))
+			return
+		}
+		parts[i] = unescaped
+	}
+	if len(parts) < 2 {
+		writeError(w, http.StatusNotFound, "no API endpoint given")
+		return
+	}
+	parts = parts[1:]
+	if parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case len(parts) == 1 && parts[0] == "projects":
+		switch r.Method {
+		case "GET":
+			s.listProjects(w, r)
+		case "POST":
+			s.createProject(w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 2 && parts[0] == "project":
+		switch r.Method {
+		case "GET":
+			s.getProject(w, r, parts[1])
+		case "DELETE":
+			s.deleteProject(w, r, parts[1])
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 3 && parts[0] == "project" && parts[2] == "config":
+		switch r.Method {
+		case "GET":
+			s.getProjectConfig(w, r, parts[1])
+		case "PUT":
+			s.setProjectConfig(w, r, parts[1])
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 4 && parts[0] == "project" && parts[2] == "config":
+		switch r.Method {
+		case "GET":
+			s.getProjectConfigKey(w, r, parts[1], parts[3])
+		case "PUT":
+			s.setProjectConfigKey(w, r, parts[1], parts[3])
+		case "DELETE":
+			s.deleteProjectConfigKey(w, r, parts[1], parts[3])
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 3 && parts[0] == "project" && (parts[2] == "readme.md" || parts[2] == "motd.md"):
+		switch r.Method {
+		case "GET":
+			s.getProjectFile(w, r, parts[1], parts[2])
+		case "PUT":
+			s.putProjectFile(w, r, parts[1], parts[2])
+		case "DELETE":
+			s.deleteProjectFile(w, r, parts[1], parts[2])
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 3 && parts[0] == "project" && parts[2] == "jobs":
+		switch r.Method {
+		case "GET":
+			s.listJobs(w, r, parts[1])
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 2 && parts[0] == "jobs" && parts[1] == "export":
+		switch r.Method {
+		case "GET":
+			s.exportJobs(w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 2 && parts[0] == "jobs" && parts[1] == "import":
+		switch r.Method {
+		case "POST":
+			s.importJobs(w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 2 && parts[0] == "job":
+		switch r.Method {
+		case "GET":
+			s.getJob(w, r, parts[1])
+		case "DELETE":
+			s.deleteJob(w, r, parts[1])
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) >= 2 && parts[0] == "storage" && parts[1] == "keys":
+		path := normalizeKeyPath(strings.Join(parts[2:], "/"))
+		switch r.Method {
+		case "GET":
+			s.getKey(w, r, path)
+		case "POST":
+			s.createOrReplaceKey(w, r, path, false)
+		case "PUT":
+			s.createOrReplaceKey(w, r, path, true)
+		case "DELETE":
+			s.deleteKey(w, r, path)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	case len(parts) == 2 && parts[0] == "system" && parts[1] == "info":
+		switch r.Method {
+		case "GET":
+			s.getSystemInfo(w, r)
+		default:
+			writeMethodNotAllowed(w)
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no such endpoint %s", r.URL.Path))
+	}
+}
+
+func (s *Server) getSystemInfo(w http.ResponseWriter, r *http.Request) {
+	info := s.systemInfo
+	now := time.Now().UTC()
+	info.ServerTime = rundeck.SystemTimestamp{
+		Epoch:       fmt.Sprintf("%d", now.UnixNano()/int64(time.Millisecond)),
+		EpochUnit:   "ms",
+		DateTimeStr: now.Format(time.RFC3339),
+	}
+	writeXML(w, http.StatusOK, info)
+}
+
+// newUUID returns a random version 4 UUID, as Rundeck uses for job ids.
+func newUUID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+type errorResult struct {
+	XMLName    xml.Name    `xml:"result"`
+	IsError    bool        `xml:"error,attr"`
+	APIVersion string      `xml:"apiversion,attr"`
+	Error      errorDetail `xml:"error"`
+}
+
+type errorDetail struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:"message"`
+}
+
+// errorCodes gives the Rundeck error code the fake server reports for each
+// error status.
+var errorCodes = map[int]string{
+	http.StatusBadRequest:       "api.error.invalid.request",
+	http.StatusForbidden:        "api.error.item.unauthorized",
+	http.StatusNotFound:         "api.error.item.doesnotexist",
+	http.StatusMethodNotAllowed: "api.error.invalid.request",
+	http.StatusConflict:         "api.error.item.alreadyexists",
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml;charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	code := errorCodes[status]
+	if code == "" {
+		code = "api.error.unknown"
+	}
+	body, _ := xml.Marshal(errorResult{
+		IsError:    true,
+		APIVersion: apiVersion,
+		Error: errorDetail{
+			Code:    code,
+			Message: message,
+		},
+	})
+	w.Header().Set("Content-Type", "text/xml;charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func readXMLBody(r *http.Request, v interface{}) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		// The client sends an empty body for an empty map, such as when
+		// clearing a project's config.
+		return nil
+	}
+	return xml.Unmarshal(body, v)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
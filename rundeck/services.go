@@ -0,0 +1,86 @@
+package rundeck
+
+import (
+	"io"
+)
+
+// JobService is the subset of the API that deals with job definitions.
+type JobService interface {
+	GetJobSummariesForProject(projectName string) ([]JobSummary, error)
+	GetJobsForProject(projectName string) ([]JobDetail, error)
+	GetJob(id string) (*JobDetail, error)
+	CreateJob(job *JobDetail) (*JobSummary, error)
+	CreateOrUpdateJob(job *JobDetail) (*JobSummary, error)
+	DeleteJob(id string) error
+}
+
+// ProjectService is the subset of the API that deals with projects.
+type ProjectService interface {
+	GetAllProjects() ([]ProjectSummary, error)
+	GetProject(name string) (*Project, error)
+	CreateProject(project *Project) (*Project, error)
+	DeleteProject(name string) error
+	SetProjectConfig(projectName string, config ProjectConfig) error
+	GetProjectConfig(projectName string) (ProjectConfig, error)
+	GetProjectConfigKey(projectName string, key string) (string, error)
+	SetProjectConfigKey(projectName string, key string, value string) error
+	DeleteProjectConfigKey(projectName string, key string) error
+	MergeProjectConfig(projectName string, set ProjectConfig, remove []string) error
+	GetProjectReadme(projectName string) (string, error)
+	SetProjectReadme(projectName string, content string) error
+	DeleteProjectReadme(projectName string) error
+	GetProjectMOTD(projectName string) (string, error)
+	SetProjectMOTD(projectName string, content string) error
+	DeleteProjectMOTD(projectName string) error
+	GetProjectSettings(projectName string) (*ProjectSettings, error)
+	UpdateProjectSettings(projectName string, settings *ProjectSettings) error
+	ListResourceSources(projectName string) ([]ResourceSource, error)
+	SetResourceSources(projectName string, sources []ResourceSource) error
+	AddResourceSource(projectName string, source ResourceSource) (int, error)
+	RemoveResourceSource(projectName string, number int) error
+	ReorderResourceSources(projectName string, order []int) error
+}
+
+// KeyStorage is the subset of the API that deals with the Rundeck key store.
+type KeyStorage interface {
+	GetKeyMeta(path string) (*KeyMeta, error)
+	GetKeysInDirMeta(path string) ([]KeyMeta, error)
+	GetKeyContent(path string) (string, error)
+	CreatePublicKey(path string, content string) error
+	ReplacePublicKey(path string, content string) error
+	CreatePrivateKey(path string, content string) error
+	ReplacePrivateKey(path string, content string) error
+	CreatePassword(path string, content string) error
+	ReplacePassword(path string, content string) error
+	DeleteKey(path string) error
+	GetKeyContentBytes(path string) ([]byte, error)
+	CopyKeyContent(path string, w io.Writer) (int64, error)
+	CreateKey(path string, kind KeyKind, content io.Reader) error
+	ReplaceKey(path string, kind KeyKind, content io.Reader) error
+	CreateSSHKeyPair(path string, opts SSHKeyOptions) (string, error)
+	WalkKeys(root string, fn KeyWalkFunc) error
+	WalkKeysParallel(root string, parallelism int, fn KeyWalkFunc) error
+	GetKeyManifest(root string) (*KeyManifest, error)
+	PlanKeySync(root string, desired map[string]KeySpec, opts KeySyncOptions) (*KeySyncPlan, error)
+	ApplyKeySync(plan *KeySyncPlan) error
+	SyncKeys(root string, desired map[string]KeySpec, opts KeySyncOptions) (*KeySyncPlan, error)
+}
+
+// SystemService is the subset of the API that describes the Rundeck server
+// itself.
+type SystemService interface {
+	GetSystemInfo() (*SystemInfo, error)
+}
+
+// API is the full set of operations offered by Client, for code that wants to
+// depend on an interface so that it can be tested with a mock such as the
+// one in package rundecktest. Code that needs only some of the operations
+// should prefer to depend on the smaller service interfaces.
+type API interface {
+	JobService
+	ProjectService
+	KeyStorage
+	SystemService
+}
+
+var _ API = (*Client)(nil)
@@ -0,0 +1,205 @@
+package rundeck
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SSHKeyAlgorithm selects the type of key generated by GenerateSSHKeyPair.
+type SSHKeyAlgorithm string
+
+// The supported SSH key algorithms.
+const (
+	SSHKeyRSA     SSHKeyAlgorithm = "rsa"
+	SSHKeyEd25519 SSHKeyAlgorithm = "ed25519"
+)
+
+// defaultRSABits is the RSA key size used when none is given.
+const defaultRSABits = 4096
+
+// SSHKeyOptions describes an SSH key pair to generate.
+type SSHKeyOptions struct {
+	// Algorithm is the type of key to generate. The default is RSA, which
+	// every Rundeck version can use. Ed25519 keys can be used only by a
+	// Rundeck built with an SSH library that supports them; the JSch
+	// library in Rundeck 2.x cannot load them.
+	Algorithm SSHKeyAlgorithm
+
+	// Bits is the size of RSA keys. The default is 4096, and sizes below
+	// 2048 are rejected. It is ignored for Ed25519 keys.
+	Bits int
+
+	// Comment is appended to the authorized_keys line and stored in
+	// Ed25519 private keys, conventionally identifying the key's owner.
+	Comment string
+}
+
+// SSHKeyPair is a generated SSH key pair.
+type SSHKeyPair struct {
+	// PrivateKeyPEM is the private key in the PEM format expected by SSH
+	// clients: PKCS #1 for RSA keys, and the OpenSSH format for Ed25519
+	// keys.
+	PrivateKeyPEM []byte
+
+	// AuthorizedKey is the public key as a line for an authorized_keys file,
+	// without a trailing newline.
+	AuthorizedKey string
+}
+
+// GenerateSSHKeyPair generates a new SSH key pair.
+func GenerateSSHKeyPair(opts SSHKeyOptions) (*SSHKeyPair, error) {
+	switch opts.Algorithm {
+	case SSHKeyRSA, "":
+		return generateRSAKeyPair(opts)
+	case SSHKeyEd25519:
+		return generateEd25519KeyPair(opts)
+	}
+	return nil, fmt.Errorf("unsupported SSH key algorithm %q", opts.Algorithm)
+}
+
+func generateRSAKeyPair(opts SSHKeyOptions) (*SSHKeyPair, error) {
+	bits := opts.Bits
+	if bits == 0 {
+		bits = defaultRSABits
+	}
+	if bits < 2048 {
+		return nil, fmt.Errorf("RSA keys must be at least 2048 bits")
+	}
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := &sshWriter{}
+	pub.writeString([]byte("ssh-rsa"))
+	pub.writeMPInt(big.NewInt(int64(key.E)))
+	pub.writeMPInt(key.N)
+
+	return &SSHKeyPair{
+		PrivateKeyPEM: pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}),
+		AuthorizedKey: authorizedKeyLine("ssh-rsa", pub.Bytes(), opts.Comment),
+	}, nil
+}
+
+func generateEd25519KeyPair(opts SSHKeyOptions) (*SSHKeyPair, error) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := &sshWriter{}
+	pub.writeString([]byte("ssh-ed25519"))
+	pub.writeString(pubKey)
+
+	// The OpenSSH private key format is described in PROTOCOL.key in the
+	// OpenSSH source. We never encrypt the key, since Rundeck protects it.
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, err
+	}
+	priv := &sshWriter{}
+	priv.Write(check[:])
+	priv.Write(check[:])
+	priv.writeString([]byte("ssh-ed25519"))
+	priv.writeString(pubKey)
+	priv.writeString(privKey)
+	priv.writeString([]byte(opts.Comment))
+	for i := byte(1); priv.Len()%8 != 0; i++ {
+		priv.WriteByte(i)
+	}
+
+	blob := &sshWriter{}
+	blob.WriteString("openssh-key-v1\x00")
+	blob.writeString([]byte("none")) // cipher
+	blob.writeString([]byte("none")) // KDF
+	blob.writeString(nil)            // KDF options
+	blob.writeUint32(1)              // number of keys
+	blob.writeString(pub.Bytes())
+	blob.writeString(priv.Bytes())
+
+	return &SSHKeyPair{
+		PrivateKeyPEM: pem.EncodeToMemory(&pem.Block{
+			Type:  "OPENSSH PRIVATE KEY",
+			Bytes: blob.Bytes(),
+		}),
+		AuthorizedKey: authorizedKeyLine("ssh-ed25519", pub.Bytes(), opts.Comment),
+	}, nil
+}
+
+func authorizedKeyLine(keyType string, pub []byte, comment string) string {
+	line := keyType + " " + base64.StdEncoding.EncodeToString(pub)
+	if comment = strings.TrimSpace(comment); comment != "" {
+		line += " " + comment
+	}
+	return line
+}
+
+// sshWriter builds data in the SSH wire format described in RFC 4251.
+type sshWriter struct {
+	bytes.Buffer
+}
+
+func (w *sshWriter) writeUint32(v uint32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	w.Write(b[:])
+}
+
+func (w *sshWriter) writeString(s []byte) {
+	w.writeUint32(uint32(len(s)))
+	w.Write(s)
+}
+
+// writeMPInt writes a non-negative integer in two's complement form,
+// with a leading zero byte if the most significant bit would otherwise be
+// set.
+func (w *sshWriter) writeMPInt(n *big.Int) {
+	b := n.Bytes()
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		b = append([]byte{0}, b...)
+	}
+	w.writeString(b)
+}
+
+// CreateSSHKeyPair generates a new SSH key pair, stores the private key at
+// the given keystore path and the public key at the same path with ".pub"
+// appended, and returns the authorized_keys line for the public key so that
+// it can be distributed to nodes.
+//
+// If the public key can't be stored, the private key is deleted again so
+// that no half-created pair is left behind.
+func (c *Client) CreateSSHKeyPair(path string, opts SSHKeyOptions) (string, error) {
+	privPath, err := ParseKeyPath(path)
+	if err != nil {
+		return "", err
+	}
+	if privPath.IsRoot() {
+		return "", &KeyPathError{path, "does not name a key"}
+	}
+	pubPath := privPath.Parent().Join(privPath.Base() + ".pub")
+
+	pair, err := GenerateSSHKeyPair(opts)
+	if err != nil {
+		return "", err
+	}
+	if err := c.CreateKey(privPath.String(), KeyKindPrivate, bytes.NewReader(pair.PrivateKeyPEM)); err != nil {
+		return "", err
+	}
+	if err := c.CreatePublicKey(pubPath.String(), pair.AuthorizedKey+"\n"); err != nil {
+		c.DeleteKey(privPath.String())
+		return "", err
+	}
+	return pair.AuthorizedKey, nil
+}
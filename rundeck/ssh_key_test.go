@@ -0,0 +1,205 @@
+package rundeck_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/apparentlymart/go-rundeck-api/rundeck"
+)
+
+// sshReader reads data in the SSH wire format.
+type sshReader struct {
+	t    *testing.T
+	data []byte
+}
+
+func (r *sshReader) uint32() uint32 {
+	if len(r.data) < 4 {
+		r.t.Fatalf("truncated integer in %x", r.data)
+	}
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return v
+}
+
+func (r *sshReader) string() []byte {
+	n := r.uint32()
+	if uint32(len(r.data)) < n {
+		r.t.Fatalf("truncated string in %x", r.data)
+	}
+	v := r.data[:n]
+	r.data = r.data[n:]
+	return v
+}
+
+func parseAuthorizedKey(t *testing.T, line string) (string, [][]byte, string) {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) != 3 {
+		t.Fatalf("authorized key %q does not have three fields", line)
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		t.Fatalf("invalid base64 in authorized key: %s", err)
+	}
+	r := &sshReader{t: t, data: blob}
+	if keyType := string(r.string()); keyType != fields[0] {
+		t.Errorf("key blob has type %q, but line has %q", keyType, fields[0])
+	}
+	var parts [][]byte
+	for len(r.data) > 0 {
+		parts = append(parts, r.string())
+	}
+	return fields[0], parts, fields[2]
+}
+
+func TestGenerateSSHKeyPairRSA(t *testing.T) {
+	// RSA is the default algorithm.
+	pair, err := rundeck.GenerateSSHKeyPair(rundeck.SSHKeyOptions{
+		Bits:    2048,
+		Comment: "rundeck@example",
+	})
+	if err != nil {
+		t.Fatalf("error generating key: %s", err)
+	}
+
+	block, _ := pem.Decode(pair.PrivateKeyPEM)
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		t.Fatalf("private key is not an RSA PEM block:\n%s", pair.PrivateKeyPEM)
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("invalid private key: %s", err)
+	}
+	if key.N.BitLen() != 2048 {
+		t.Errorf("got %d-bit key, but wanted 2048", key.N.BitLen())
+	}
+
+	keyType, parts, comment := parseAuthorizedKey(t, pair.AuthorizedKey)
+	if keyType != "ssh-rsa" || comment != "rundeck@example" || len(parts) != 2 {
+		t.Fatalf("unexpected authorized key %q", pair.AuthorizedKey)
+	}
+	if e := new(big.Int).SetBytes(parts[0]); e.Int64() != int64(key.E) {
+		t.Errorf("got exponent %s, but wanted %d", e, key.E)
+	}
+	// The modulus has its top bit set, so it needs a leading zero byte to
+	// remain positive.
+	if parts[1][0] != 0 || new(big.Int).SetBytes(parts[1]).Cmp(key.N) != 0 {
+		t.Errorf("modulus in authorized key does not match private key")
+	}
+
+	if _, err := rundeck.GenerateSSHKeyPair(rundeck.SSHKeyOptions{Algorithm: rundeck.SSHKeyRSA, Bits: 1024}); err == nil {
+		t.Errorf("generating 1024-bit key succeeded; want error")
+	}
+}
+
+func TestGenerateSSHKeyPairEd25519(t *testing.T) {
+	pair, err := rundeck.GenerateSSHKeyPair(rundeck.SSHKeyOptions{
+		Algorithm: rundeck.SSHKeyEd25519,
+		Comment:   "rundeck@example",
+	})
+	if err != nil {
+		t.Fatalf("error generating key: %s", err)
+	}
+
+	keyType, parts, comment := parseAuthorizedKey(t, pair.AuthorizedKey)
+	if keyType != "ssh-ed25519" || comment != "rundeck@example" || len(parts) != 1 {
+		t.Fatalf("unexpected authorized key %q", pair.AuthorizedKey)
+	}
+	pubKey := ed25519.PublicKey(parts[0])
+
+	block, _ := pem.Decode(pair.PrivateKeyPEM)
+	if block == nil || block.Type != "OPENSSH PRIVATE KEY" {
+		t.Fatalf("private key is not an OpenSSH PEM block:\n%s", pair.PrivateKeyPEM)
+	}
+	magic := "openssh-key-v1\x00"
+	if !bytes.HasPrefix(block.Bytes, []byte(magic)) {
+		t.Fatalf("private key does not start with the OpenSSH magic")
+	}
+	r := &sshReader{t: t, data: block.Bytes[len(magic):]}
+	if cipher, kdf, kdfOpts := r.string(), r.string(), r.string(); string(cipher) != "none" || string(kdf) != "none" || len(kdfOpts) != 0 {
+		t.Errorf("private key is encrypted")
+	}
+	if n := r.uint32(); n != 1 {
+		t.Fatalf("private key contains %d keys, but wanted 1", n)
+	}
+	if pubBlob, _ := base64.StdEncoding.DecodeString(strings.Fields(pair.AuthorizedKey)[1]); !bytes.Equal(r.string(), pubBlob) {
+		t.Errorf("public key in private key file does not match authorized key")
+	}
+
+	priv := r.string()
+	if len(r.data) != 0 {
+		t.Errorf("unexpected trailing data in private key")
+	}
+	if len(priv)%8 != 0 {
+		t.Errorf("private section has length %d, which is not a multiple of 8", len(priv))
+	}
+	r = &sshReader{t: t, data: priv}
+	if check1, check2 := r.uint32(), r.uint32(); check1 != check2 {
+		t.Errorf("check integers do not match")
+	}
+	inner := [][]byte{r.string(), r.string(), r.string(), r.string()}
+	if string(inner[0]) != "ssh-ed25519" || !bytes.Equal(inner[1], pubKey) || string(inner[3]) != comment {
+		t.Fatalf("unexpected private key section %q", inner)
+	}
+	for i, b := range r.data {
+		if b != byte(i+1) {
+			t.Errorf("invalid padding %x", r.data)
+			break
+		}
+	}
+	privKey := ed25519.PrivateKey(inner[2])
+	if !bytes.Equal(privKey.Public().(ed25519.PublicKey), pubKey) {
+		t.Errorf("private key does not match public key")
+	}
+	msg := []byte("hello")
+	if !ed25519.Verify(pubKey, msg, ed25519.Sign(privKey, msg)) {
+		t.Errorf("signature made with private key does not verify")
+	}
+}
+
+func TestCreateSSHKeyPair(t *testing.T) {
+	server, client := newKeyWalkServer(t)
+	defer server.Close()
+
+	line, err := client.CreateSSHKeyPair("nodes/web/id_rsa", rundeck.SSHKeyOptions{Bits: 2048, Comment: "web"})
+	if err != nil {
+		t.Fatalf("error creating key pair: %s", err)
+	}
+	if !strings.HasPrefix(line, "ssh-rsa ") || !strings.HasSuffix(line, " web") {
+		t.Errorf("got authorized key %q", line)
+	}
+
+	meta, err := client.GetKeyMeta("nodes/web/id_rsa")
+	if err != nil {
+		t.Fatalf("error getting private key meta: %s", err)
+	}
+	if !meta.IsPrivate() {
+		t.Errorf("private half stored as %s", meta.KeyType)
+	}
+	pub, err := client.GetKeyContent("nodes/web/id_rsa.pub")
+	if err != nil {
+		t.Fatalf("error getting public key: %s", err)
+	}
+	if pub != line+"\n" {
+		t.Errorf("got public key %q, but wanted %q", pub, line+"\n")
+	}
+
+	// The public key already exists now, so a second attempt must fail and
+	// leave no private key behind.
+	if err := client.DeleteKey("nodes/web/id_rsa"); err != nil {
+		t.Fatalf("error deleting private key: %s", err)
+	}
+	if _, err := client.CreateSSHKeyPair("nodes/web/id_rsa", rundeck.SSHKeyOptions{Bits: 2048}); err == nil {
+		t.Fatalf("creating key pair over existing public key succeeded; want error")
+	}
+	if _, ok := server.KeyContent("nodes/web/id_rsa"); ok {
+		t.Errorf("private key left behind after failure")
+	}
+}
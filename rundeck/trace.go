@@ -0,0 +1,58 @@
+package rundeck
+
+// Tracer creates spans to trace API requests. Its design follows
+// OpenTelemetry, so that an adapter for an OpenTelemetry tracer is trivial to
+// write, but this package does not depend on any tracing library.
+type Tracer interface {
+	// StartSpan begins a span with the given name and initial attributes.
+	StartSpan(name string, attributes map[string]interface{}) Span
+}
+
+// Span is a single traced operation, created by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute on the span.
+	SetAttribute(key string, value interface{})
+
+	// RecordError records that the operation failed with the given error.
+	RecordError(err error)
+
+	// End marks the operation as complete.
+	End()
+}
+
+// Attribute names used on spans created for API requests.
+const (
+	SpanAttrMethod     = "http.method"
+	SpanAttrStatusCode = "http.status_code"
+	SpanAttrEndpoint   = "rundeck.endpoint"
+	SpanAttrProject    = "rundeck.project"
+)
+
+// startSpan starts a span for the request described by ev, or returns nil if
+// the client has no tracer.
+func (c *Client) startSpan(ev *RequestEvent) Span {
+	if c.tracer == nil {
+		return nil
+	}
+	attrs := map[string]interface{}{
+		SpanAttrMethod:   ev.Method,
+		SpanAttrEndpoint: ev.Endpoint,
+	}
+	if ev.Project != "" {
+		attrs[SpanAttrProject] = ev.Project
+	}
+	return c.tracer.StartSpan("rundeck "+ev.Method+" "+ev.Endpoint, attrs)
+}
+
+func endSpan(span Span, ev *RequestEvent) {
+	if span == nil {
+		return
+	}
+	if ev.StatusCode != 0 {
+		span.SetAttribute(SpanAttrStatusCode, ev.StatusCode)
+	}
+	if ev.Err != nil {
+		span.RecordError(ev.Err)
+	}
+	span.End()
+}
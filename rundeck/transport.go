@@ -0,0 +1,75 @@
+package rundeck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+)
+
+// transport builds the http.RoundTripper described by the configuration.
+func (config *ClientConfig) transport() (http.RoundTripper, error) {
+	t := config.Transport
+	if t == nil {
+		tlsConfig, err := config.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		t = &http.Transport{
+			TLSClientConfig:     tlsConfig,
+			Proxy:               config.Proxy,
+			MaxIdleConns:        config.MaxIdleConns,
+			MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+			MaxConnsPerHost:     config.MaxConnsPerHost,
+			IdleConnTimeout:     config.IdleConnTimeout,
+		}
+	}
+
+	for _, wrap := range config.Middleware {
+		t = wrap(t)
+	}
+	return t, nil
+}
+
+func (config *ClientConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if config.TLSConfig != nil {
+		tlsConfig = config.TLSConfig.Clone()
+	}
+	if config.AllowUnverifiedSSL {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	if len(config.CACertificatesPEM) > 0 {
+		// The pool is always a new one, since Clone doesn't copy RootCAs
+		// and the caller's pool must not be modified.
+		var pool *x509.CertPool
+		if tlsConfig.RootCAs != nil {
+			pool = tlsConfig.RootCAs.Clone()
+		} else {
+			var err error
+			pool, err = x509.SystemCertPool()
+			if err != nil {
+				// Some platforms can't provide the system pool, in which
+				// case we trust only the given certificates.
+				pool = x509.NewCertPool()
+			}
+		}
+		if !pool.AppendCertsFromPEM(config.CACertificatesPEM) {
+			return nil, fmt.Errorf("no valid certificates found in CACertificatesPEM")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.ClientCertificatePEM) > 0 || len(config.ClientKeyPEM) > 0 {
+		cert, err := tls.X509KeyPair(config.ClientCertificatePEM, config.ClientKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %s", err.Error())
+		}
+		// Use a full slice expression so we never append into the caller's array.
+		certs := tlsConfig.Certificates
+		tlsConfig.Certificates = append(certs[:len(certs):len(certs)], cert)
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,154 @@
+package rundeck
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientMutualTLS(t *testing.T) {
+	certPEM, keyPEM := testSelfSignedCert(t, "rundeck-client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certPEM)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<system><rundeck><version>2.6.0</version><apiversion>13</apiversion></rundeck></system>`))
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+	serverCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	requests := 0
+	countRequests := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return next.RoundTrip(req)
+		})
+	}
+
+	client, err := NewClient(&ClientConfig{
+		BaseURL:              server.URL,
+		CACertificatesPEM:    serverCertPEM,
+		ClientCertificatePEM: certPEM,
+		ClientKeyPEM:         keyPEM,
+		Middleware:           []func(http.RoundTripper) http.RoundTripper{countRequests},
+	})
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	info, err := client.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("error making request: %s", err)
+	}
+	if info.Rundeck.APIVersion != 13 {
+		t.Errorf("got API version %d, but wanted 13", info.Rundeck.APIVersion)
+	}
+	if requests != 1 {
+		t.Errorf("middleware saw %d requests, but wanted 1", requests)
+	}
+
+	// Without the client certificate the server must refuse the connection.
+	client, err = NewClient(&ClientConfig{
+		BaseURL:           server.URL,
+		CACertificatesPEM: serverCertPEM,
+	})
+	if err != nil {
+		t.Fatalf("error creating client: %s", err)
+	}
+	if _, err := client.GetSystemInfo(); err == nil {
+		t.Errorf("request without client certificate succeeded; want error")
+	}
+}
+
+func TestClientConfigInvalidTLS(t *testing.T) {
+	_, err := NewClient(&ClientConfig{
+		BaseURL:           "https://rundeck.example.com/",
+		CACertificatesPEM: []byte("not a certificate"),
+	})
+	if err == nil {
+		t.Errorf("invalid CA certificates accepted; want error")
+	}
+
+	certPEM, _ := testSelfSignedCert(t, "rundeck-client")
+	_, err = NewClient(&ClientConfig{
+		BaseURL:              "https://rundeck.example.com/",
+		ClientCertificatePEM: certPEM,
+	})
+	if err == nil {
+		t.Errorf("client certificate without key accepted; want error")
+	}
+}
+
+func TestClientConfigTLSConfigNotModified(t *testing.T) {
+	callerCertPEM, _ := testSelfSignedCert(t, "caller-ca")
+	extraCertPEM, _ := testSelfSignedCert(t, "extra-ca")
+
+	callerPool := x509.NewCertPool()
+	callerPool.AppendCertsFromPEM(callerCertPEM)
+	before := callerPool.Clone()
+
+	config := &ClientConfig{
+		TLSConfig:         &tls.Config{RootCAs: callerPool},
+		CACertificatesPEM: extraCertPEM,
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		t.Fatalf("error building TLS config: %s", err)
+	}
+
+	if !callerPool.Equal(before) {
+		t.Errorf("caller's RootCAs pool was modified")
+	}
+	if tlsConfig.RootCAs == callerPool {
+		t.Errorf("TLS config shares the caller's RootCAs pool")
+	}
+	if tlsConfig.RootCAs.Equal(before) {
+		t.Errorf("TLS config RootCAs doesn't include CACertificatesPEM")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func testSelfSignedCert(t *testing.T, commonName string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
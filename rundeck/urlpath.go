@@ -0,0 +1,41 @@
+package rundeck
+
+import (
+	"net/url"
+	"strings"
+)
+
+// escapePathSegment escapes a single path segment so that it is always
+// treated as exactly one segment, whatever characters it contains. Slashes
+// are escaped along with everything else that is special in a path, and the
+// dot segments are escaped so that they are not resolved away.
+func escapePathSegment(seg string) string {
+	switch seg {
+	case ".":
+		return "%2E"
+	case "..":
+		return "%2E%2E"
+	}
+	return url.PathEscape(seg)
+}
+
+// appendURLPath returns a copy of base with the given path segments appended
+// to its path, each escaped individually. Hierarchical values such as key
+// store paths must be split into their segments by the caller.
+func appendURLPath(base *url.URL, parts []string) *url.URL {
+	escaped := make([]string, len(parts))
+	for i, part := range parts {
+		escaped[i] = escapePathSegment(part)
+	}
+
+	basePath, baseRawPath := base.Path, base.EscapedPath()
+	if !strings.HasSuffix(basePath, "/") {
+		basePath += "/"
+		baseRawPath += "/"
+	}
+
+	u := *base
+	u.Path = basePath + strings.Join(parts, "/")
+	u.RawPath = baseRawPath + strings.Join(escaped, "/")
+	return &u
+}
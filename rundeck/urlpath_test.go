@@ -0,0 +1,114 @@
+package rundeck
+
+import (
+	"testing"
+)
+
+func TestMakeHTTPRequestEscaping(t *testing.T) {
+	client, err := NewClient(&ClientConfig{BaseURL: "https://rundeck.example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Name      string
+		PathParts []string
+		Query     map[string]string
+		WantURL   string
+		WantPath  string
+	}{
+		{
+			"plain",
+			[]string{"project", "example", "jobs"},
+			nil,
+			"https://rundeck.example.com/api/13/project/example/jobs",
+			"/api/13/project/example/jobs",
+		},
+		{
+			"space",
+			[]string{"project", "my project", "jobs"},
+			nil,
+			"https://rundeck.example.com/api/13/project/my%20project/jobs",
+			"/api/13/project/my project/jobs",
+		},
+		{
+			"hash and question mark",
+			[]string{"job", "id#1?x=y"},
+			nil,
+			"https://rundeck.example.com/api/13/job/id%231%3Fx=y",
+			"/api/13/job/id#1?x=y",
+		},
+		{
+			"percent",
+			[]string{"project", "100%"},
+			nil,
+			"https://rundeck.example.com/api/13/project/100%25",
+			"/api/13/project/100%",
+		},
+		{
+			"slash within segment",
+			[]string{"project", "a/b", "config"},
+			nil,
+			"https://rundeck.example.com/api/13/project/a%2Fb/config",
+			"/api/13/project/a/b/config",
+		},
+		{
+			"dot segments",
+			[]string{"job", "..", "."},
+			nil,
+			"https://rundeck.example.com/api/13/job/%2E%2E/%2E",
+			"/api/13/job/../.",
+		},
+		{
+			"unicode",
+			[]string{"project", "café"},
+			nil,
+			"https://rundeck.example.com/api/13/project/caf%C3%A9",
+			"/api/13/project/café",
+		},
+		{
+			"hierarchical key path",
+			KeyPath("team a/ssh #2/id_rsa").pathParts(),
+			nil,
+			"https://rundeck.example.com/api/13/storage/keys/team%20a/ssh%20%232/id_rsa",
+			"/api/13/storage/keys/team a/ssh #2/id_rsa",
+		},
+		{
+			"query",
+			[]string{"jobs", "export"},
+			map[string]string{"project": "a&b=c d"},
+			"https://rundeck.example.com/api/13/jobs/export?project=a%26b%3Dc+d",
+			"/api/13/jobs/export",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := (&request{
+				Method:    "GET",
+				PathParts: test.PathParts,
+				QueryArgs: test.Query,
+			}).MakeHTTPRequest(client)
+			if got := req.URL.String(); got != test.WantURL {
+				t.Errorf("got URL %s, but wanted %s", got, test.WantURL)
+			}
+			if got := req.URL.Path; got != test.WantPath {
+				t.Errorf("got path %q, but wanted %q", got, test.WantPath)
+			}
+		})
+	}
+}
+
+func TestMakeHTTPRequestBasePath(t *testing.T) {
+	client, err := NewClient(&ClientConfig{BaseURL: "https://example.com/rundeck%20prod/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (&request{
+		Method:    "GET",
+		PathParts: []string{"project", "a b"},
+	}).MakeHTTPRequest(client)
+	if got, want := req.URL.String(), "https://example.com/rundeck%20prod/api/13/project/a%20b"; got != want {
+		t.Errorf("got URL %s, but wanted %s", got, want)
+	}
+}
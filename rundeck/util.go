@@ -6,6 +6,15 @@ import (
 	"sort"
 )
 
+// XMLElement is an arbitrary XML element, used to retain elements that this
+// package does not otherwise understand so that they can be written back out
+// unchanged.
+type XMLElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content []byte     `xml:",innerxml"`
+}
+
 func marshalMapToXML(c *map[string]string, e *xml.Encoder, start xml.StartElement, entryName string, keyName string, valueName string) error {
 	if len(*c) == 0 {
 		return nil
@@ -79,3 +88,58 @@ func unmarshalMapFromXML(c *map[string]string, d *xml.Decoder, start xml.StartEl
 		}
 	}
 }
+
+// marshalMapToXMLElements writes a map as a sequence of child elements of start,
+// each named after a key and containing its value as character data. Unlike
+// marshalMapToXML, the containing element is written even if the map is empty.
+func marshalMapToXMLElements(c *map[string]string, e *xml.Encoder, start xml.StartElement) error {
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	// Sort the keys so we'll have a deterministic result.
+	keys := []string{}
+	for k := range *c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		err = e.EncodeElement((*c)[k], xml.StartElement{Name: xml.Name{Local: k}})
+		if err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+func unmarshalMapFromXMLElements(c *map[string]string, d *xml.Decoder, start xml.StartElement) error {
+	result := map[string]string{}
+	for {
+		token, err := d.Token()
+		if token == nil {
+			err = fmt.Errorf("EOF while decoding %s", start.Name.Local)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		default:
+			continue
+		case xml.StartElement:
+			var v string
+			err = d.DecodeElement(&v, &t)
+			if err != nil {
+				return err
+			}
+			result[t.Name.Local] = v
+		case xml.EndElement:
+			if t.Name.Local == start.Name.Local {
+				*c = result
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package rundeck
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var jobLogLevels = map[string]bool{
+	"DEBUG":   true,
+	"VERBOSE": true,
+	"INFO":    true,
+	"WARN":    true,
+	"ERROR":   true,
+}
+
+var jobOrderingStrategies = map[string]bool{
+	"node-first": true,
+	"step-first": true,
+	"parallel":   true,
+}
+
+var jobRankOrders = map[string]bool{
+	"ascending":  true,
+	"descending": true,
+}
+
+// Timeouts are a number of seconds or a sequence of durations like
+// "1h 30m". Values containing option references, such as "${option.t}m",
+// are only checked once the job runs.
+var jobTimeoutPattern = regexp.MustCompile(`^(\d+[smhdwy]?\s*)+$`)
+
+// Retry counts are either a number or a reference to a job option.
+var jobRetryPattern = regexp.MustCompile(`^(\d+|\$\{option\.[^}]+\})$`)
+
+// Validate checks the job definition against the rules that Rundeck enforces
+// when importing a job, so that mistakes can be caught before making a
+// request to the server.
+//
+// Option validation regexes are not checked, since Rundeck uses Java regular
+// expressions, which Go can't compile reliably; an invalid regex is reported
+// only by the server.
+//
+// If any problems are found, the result is a *JobValidationError describing
+// all of them. Otherwise the result is nil.
+func (job *JobDetail) Validate() error {
+	v := &jobValidator{}
+
+	if job.Name == "" {
+		v.addf("name is required")
+	}
+	if job.LogLevel != "" && !jobLogLevels[job.LogLevel] {
+		v.addf("loglevel %q is not one of DEBUG, VERBOSE, INFO, WARN or ERROR", job.LogLevel)
+	}
+	if job.Timeout != "" && !strings.Contains(job.Timeout, "${") && !jobTimeoutPattern.MatchString(job.Timeout) {
+		v.addf("timeout %q must be a number of seconds or a duration like \"1h 30m\"", job.Timeout)
+	}
+	if job.Retry != "" && !jobRetryPattern.MatchString(job.Retry) {
+		v.addf("retry %q must be a whole number or an option reference", job.Retry)
+	}
+	if job.Dispatch != nil {
+		v.validateDispatch("dispatch", job.Dispatch)
+	}
+	if job.OptionsConfig != nil {
+		v.validateOptions(job.OptionsConfig)
+	}
+	if job.CommandSequence == nil || len(job.CommandSequence.Commands) == 0 {
+		v.addf("sequence must contain at least one command")
+	} else {
+		v.validateSequence(job.CommandSequence)
+	}
+
+	if len(v.problems) > 0 {
+		return &JobValidationError{
+			Problems: v.problems,
+		}
+	}
+	return nil
+}
+
+type jobValidator struct {
+	problems []string
+}
+
+func (v *jobValidator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *jobValidator) validateDispatch(context string, dispatch *JobDispatch) {
+	if dispatch.MaxThreadCount < 0 {
+		v.addf("%s threadcount must not be negative", context)
+	}
+	if dispatch.RankOrder != "" && !jobRankOrders[dispatch.RankOrder] {
+		v.addf("%s rankOrder %q must be either \"ascending\" or \"descending\"", context, dispatch.RankOrder)
+	}
+}
+
+func (v *jobValidator) validateOptions(options *JobOptions) {
+	seen := map[string]bool{}
+	for i, option := range options.Options {
+		context := fmt.Sprintf("option %d", i+1)
+		if option.Name == "" {
+			v.addf("%s must have a name", context)
+		} else {
+			context = fmt.Sprintf("option %q", option.Name)
+			if seen[option.Name] {
+				v.addf("%s is defined more than once", context)
+			}
+			seen[option.Name] = true
+		}
+
+		if len(option.ValueChoices) > 0 && option.ValueChoicesURL != "" {
+			v.addf("%s cannot set both values and valuesUrl", context)
+		}
+		if option.RequirePredefinedChoice && len(option.ValueChoices) == 0 && option.ValueChoicesURL == "" {
+			v.addf("%s enforces predefined values but has neither values nor valuesUrl", context)
+		}
+		if option.AllowsMultipleValues && option.MultiValueDelimiter == "" {
+			v.addf("%s is multivalued but has no delimiter", context)
+		}
+		if option.ObscureInput && option.DefaultValue != "" {
+			v.addf("%s is secure and so cannot have a default value; use storagePath instead", context)
+		}
+		// ValidationRegex isn't checked, since Rundeck uses Java regular
+		// expressions and Go's regexp rejects many valid ones.
+	}
+}
+
+func (v *jobValidator) validateSequence(seq *JobCommandSequence) {
+	var strategyConfig JobWorkflowStrategyConfig
+	if seq.PluginConfig != nil {
+		strategyConfig = seq.PluginConfig.WorkflowStrategy
+	}
+	if seq.OrderingStrategy != "" && !jobOrderingStrategies[seq.OrderingStrategy] {
+		// Third-party strategy plugins are allowed, but only if they are configured.
+		if _, configured := strategyConfig[seq.OrderingStrategy]; !configured {
+			v.addf("sequence strategy %q is not a built-in strategy and has no WorkflowStrategy configuration", seq.OrderingStrategy)
+		} else if seq.OrderingStrategy == "ruleset" && strategyConfig["ruleset"]["rules"] == "" {
+			v.addf("sequence strategy \"ruleset\" has no rules in its WorkflowStrategy configuration")
+		}
+	}
+	if seq.PluginConfig != nil {
+		v.validateLogFilters("sequence", seq.PluginConfig.LogFilters)
+	}
+	for i := range seq.Commands {
+		v.validateCommand(fmt.Sprintf("command %d", i+1), &seq.Commands[i], false)
+	}
+}
+
+func (v *jobValidator) validateCommand(context string, cmd *JobCommand, isErrorHandler bool) {
+	kinds := []string{}
+	if cmd.ShellCommand != "" {
+		kinds = append(kinds, "exec")
+	}
+	if cmd.Script != "" {
+		kinds = append(kinds, "script")
+	}
+	if cmd.ScriptFile != "" {
+		kinds = append(kinds, "scriptfile")
+	}
+	if cmd.Job != nil {
+		kinds = append(kinds, "jobref")
+	}
+	if cmd.StepPlugin != nil {
+		kinds = append(kinds, "step-plugin")
+	}
+	if cmd.NodeStepPlugin != nil {
+		kinds = append(kinds, "node-step-plugin")
+	}
+
+	switch len(kinds) {
+	case 0:
+		v.addf("%s must set one of exec, script, scriptfile, jobref, step-plugin or node-step-plugin", context)
+	case 1:
+		// Exactly one is what we want.
+	default:
+		v.addf("%s sets %d mutually-exclusive command types %v; only one is allowed", context, len(kinds), kinds)
+	}
+
+	isScript := cmd.Script != "" || cmd.ScriptFile != ""
+	if cmd.ScriptFileArgs != "" && !isScript {
+		v.addf("%s sets scriptargs without script or scriptfile", context)
+	}
+	if cmd.ScriptInterpreter != nil && !isScript {
+		v.addf("%s sets scriptinterpreter without script or scriptfile", context)
+	}
+	if cmd.FileExtension != "" && !isScript {
+		v.addf("%s sets fileExtension without script or scriptfile", context)
+	}
+
+	if cmd.Job != nil {
+		if cmd.Job.Name == "" {
+			v.addf("%s jobref must have a name", context)
+		}
+		if cmd.Job.Dispatch != nil {
+			v.validateDispatch(context+" jobref dispatch", cmd.Job.Dispatch)
+		}
+	}
+	if cmd.StepPlugin != nil && cmd.StepPlugin.Type == "" {
+		v.addf("%s step-plugin must have a type", context)
+	}
+	if cmd.NodeStepPlugin != nil && cmd.NodeStepPlugin.Type == "" {
+		v.addf("%s node-step-plugin must have a type", context)
+	}
+
+	if cmd.Plugins != nil {
+		v.validateLogFilters(context, cmd.Plugins.LogFilters)
+	}
+
+	if cmd.ErrorHandler != nil {
+		if isErrorHandler {
+			v.addf("%s error handler cannot have its own error handler", context)
+		} else {
+			v.validateCommand(context+" error handler", cmd.ErrorHandler, true)
+		}
+	}
+}
+
+func (v *jobValidator) validateLogFilters(context string, filters []JobLogFilter) {
+	for i, filter := range filters {
+		if filter.Type == "" {
+			v.addf("%s log filter %d must have a type", context, i+1)
+		}
+	}
+}
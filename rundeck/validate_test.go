@@ -0,0 +1,236 @@
+package rundeck
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJobDetailValidate(t *testing.T) {
+	tests := []struct {
+		Name             string
+		Input            JobDetail
+		ExpectedProblems []string
+	}{
+		{
+			"valid",
+			JobDetail{
+				Name:    "ok",
+				Timeout: "1h 30m",
+				Retry:   "${option.retries}",
+				OptionsConfig: &JobOptions{
+					Options: []JobOption{
+						JobOption{
+							Name: "retries",
+							// Java syntax that Go's regexp doesn't support.
+							ValidationRegex: `^(?!0)\d++$`,
+						},
+					},
+				},
+				CommandSequence: &JobCommandSequence{
+					OrderingStrategy: "node-first",
+					Commands: []JobCommand{
+						JobCommand{
+							ScriptFile:     "/bin/true",
+							ScriptFileArgs: "-v",
+							ErrorHandler: &JobCommand{
+								ContinueOnError: true,
+								ShellCommand:    "echo failed",
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"timeout-option",
+			JobDetail{
+				Name:    "timeout",
+				Timeout: "${option.hours}h${option.minutes}m",
+				CommandSequence: &JobCommandSequence{
+					Commands: []JobCommand{
+						JobCommand{ShellCommand: "true"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"missing-everything",
+			JobDetail{},
+			[]string{
+				"name is required",
+				"sequence must contain at least one command",
+			},
+		},
+		{
+			"bad-formats",
+			JobDetail{
+				Name:     "formats",
+				LogLevel: "LOUD",
+				Timeout:  "forever",
+				Retry:    "3x",
+				CommandSequence: &JobCommandSequence{
+					OrderingStrategy: "random",
+					Commands: []JobCommand{
+						JobCommand{ShellCommand: "true"},
+					},
+				},
+			},
+			[]string{
+				`loglevel "LOUD"`,
+				`timeout "forever"`,
+				`retry "3x"`,
+				`sequence strategy "random"`,
+			},
+		},
+		{
+			"bad-options",
+			JobDetail{
+				Name: "options",
+				OptionsConfig: &JobOptions{
+					Options: []JobOption{
+						JobOption{
+							Name:            "choices",
+							ValueChoices:    JobValueChoices{"a", "b"},
+							ValueChoicesURL: "http://example.com/",
+						},
+						JobOption{
+							Name:         "secret",
+							ObscureInput: true,
+							DefaultValue: "hunter2",
+						},
+						JobOption{
+							Name: "dup",
+						},
+						JobOption{
+							Name: "dup",
+						},
+					},
+				},
+				CommandSequence: &JobCommandSequence{
+					Commands: []JobCommand{
+						JobCommand{ShellCommand: "true"},
+					},
+				},
+			},
+			[]string{
+				`option "choices" cannot set both values and valuesUrl`,
+				`option "secret" is secure and so cannot have a default value`,
+				`option "dup" is defined more than once`,
+			},
+		},
+		{
+			"ruleset",
+			JobDetail{
+				Name: "ruleset",
+				CommandSequence: &JobCommandSequence{
+					OrderingStrategy: "ruleset",
+					Commands: []JobCommand{
+						JobCommand{ShellCommand: "true"},
+					},
+					PluginConfig: &JobWorkflowPluginConfig{
+						WorkflowStrategy: JobWorkflowStrategyConfig{
+							"ruleset": JobPluginConfig{
+								"rules": "[*] run-in-sequence",
+							},
+						},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"ruleset-without-config",
+			JobDetail{
+				Name: "ruleset",
+				CommandSequence: &JobCommandSequence{
+					OrderingStrategy: "ruleset",
+					Commands: []JobCommand{
+						JobCommand{ShellCommand: "true"},
+					},
+				},
+			},
+			[]string{
+				`sequence strategy "ruleset" is not a built-in strategy and has no WorkflowStrategy configuration`,
+			},
+		},
+		{
+			"ruleset-without-rules",
+			JobDetail{
+				Name: "ruleset",
+				CommandSequence: &JobCommandSequence{
+					OrderingStrategy: "ruleset",
+					Commands: []JobCommand{
+						JobCommand{ShellCommand: "true"},
+					},
+					PluginConfig: &JobWorkflowPluginConfig{
+						WorkflowStrategy: JobWorkflowStrategyConfig{
+							"ruleset": JobPluginConfig{},
+						},
+					},
+				},
+			},
+			[]string{
+				`sequence strategy "ruleset" has no rules`,
+			},
+		},
+		{
+			"bad-commands",
+			JobDetail{
+				Name: "commands",
+				CommandSequence: &JobCommandSequence{
+					Commands: []JobCommand{
+						JobCommand{
+							ShellCommand: "true",
+							Script:       "#!/bin/sh\ntrue",
+						},
+						JobCommand{},
+						JobCommand{
+							ShellCommand:   "true",
+							ScriptFileArgs: "-v",
+							ErrorHandler: &JobCommand{
+								NodeStepPlugin: &JobPlugin{},
+								ErrorHandler: &JobCommand{
+									ShellCommand: "true",
+								},
+							},
+						},
+					},
+				},
+			},
+			[]string{
+				"command 1 sets 2 mutually-exclusive command types [exec script]",
+				"command 2 must set one of",
+				"command 3 sets scriptargs without script or scriptfile",
+				"command 3 error handler node-step-plugin must have a type",
+				"command 3 error handler error handler cannot have its own error handler",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := test.Input.Validate()
+		if test.ExpectedProblems == nil {
+			if err != nil {
+				t.Errorf("Test %s got unexpected error: %s", test.Name, err.Error())
+			}
+			continue
+		}
+
+		valErr, ok := err.(*JobValidationError)
+		if !ok {
+			t.Errorf("Test %s got %#v, but wanted *JobValidationError", test.Name, err)
+			continue
+		}
+		if len(valErr.Problems) != len(test.ExpectedProblems) {
+			t.Errorf("Test %s got %d problems %q, but wanted %d", test.Name, len(valErr.Problems), valErr.Problems, len(test.ExpectedProblems))
+			continue
+		}
+		for i, want := range test.ExpectedProblems {
+			if !strings.HasPrefix(valErr.Problems[i], want) {
+				t.Errorf("Test %s problem %d is %q, but wanted prefix %q", test.Name, i, valErr.Problems[i], want)
+			}
+		}
+	}
+}